@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+const apiKeyEnv = "MET_OFFICE_API_KEY"
+
+type keyCheckedMsg struct {
+	key string
+	err error
+}
+
+// the keys from the environment, comma separated, or else the ones saved
+// in the config, empty if there are neither
+func getApiKeys(cfg Config) []string {
+	if env, ok := os.LookupEnv(apiKeyEnv); ok {
+		var keys []string
+		for _, key := range strings.Split(env, ",") {
+			if key = strings.TrimSpace(key); key != "" {
+				keys = append(keys, key)
+			}
+		}
+
+		return keys
+	}
+
+	var keys []string
+	if cfg.APIKey != "" {
+		keys = append(keys, cfg.APIKey)
+	}
+
+	return append(keys, cfg.APIKeys...)
+}
+
+func setupKeyInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "Met Office DataPoint API key"
+	ti.EchoMode = textinput.EchoPassword
+	ti.EchoCharacter = '•'
+	ti.Focus()
+
+	return ti
+}
+
+// ask for an API key before anything else, there's no sitelist to load
+// without one
+func askForApiKey(m model) model {
+	m.enteringKey = true
+	m.loadingSites = false
+	m.keyInput = setupKeyInput()
+
+	return m
+}
+
+// try a key against the capabilities endpoint, which is small
+func checkApiKey(m model, key string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := m.fetcher.Get(context.Background(), baseUrl+"val/wxfcs/all/json/capabilities?res=3hourly&key="+url.QueryEscape(key))
+		return keyCheckedMsg{key: key, err: err}
+	}
+}
+
+func updateKeyEntry(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			return m, tea.Quit
+		case "enter":
+			key := strings.TrimSpace(m.keyInput.Value())
+			if key == "" || m.checkingKey {
+				return m, nil
+			}
+
+			m.checkingKey = true
+			m.notice = "Checking your key…"
+
+			return m, checkApiKey(m, key)
+		}
+	case keyCheckedMsg:
+		m.checkingKey = false
+		if msg.err != nil {
+			slog.Warn("API key check failed", "err", msg.err)
+		}
+
+		var statusErr *data.StatusError
+		if errors.As(msg.err, &statusErr) && (statusErr.Code == http.StatusUnauthorized || statusErr.Code == http.StatusForbidden) {
+			m.notice = "That key wasn't accepted, check it and try again"
+			return m, nil
+		}
+
+		if msg.err != nil {
+			m.notice = describeError(msg.err)
+			return m, nil
+		}
+
+		apiKey = msg.key
+		m.enteringKey = false
+		m.loadingSites = true
+		m.sitelistRead, m.sitelistTotal = 0, 0
+		m.notice = ""
+
+		// the key works for this run either way, it'll just need
+		// entering again next time
+		if err := saveApiKey(msg.key); err != nil {
+			m.notice = "Couldn't save your key: " + err.Error()
+		}
+
+		return m, tea.Batch(m.spinner.Tick, loadSitelist(m))
+	}
+
+	var cmd tea.Cmd
+	m.keyInput, cmd = m.keyInput.Update(msg)
+
+	return m, cmd
+}
+
+func keyEntryView(m model) string {
+	text := "Enter your Met Office DataPoint API key to get started.\n" +
+		"You can register for one at https://www.metoffice.gov.uk/services/data/datapoint\n\n" +
+		borderStyle.Render(m.keyInput.View())
+
+	if m.notice != "" {
+		text += "\n" + footerView(m)
+	}
+
+	hint := lipgloss.NewStyle().Foreground(paletteColor(grey)).Render("enter: save key • esc: quit")
+
+	return listStyle.Render(wrapText(m, text) + "\n\n" + hint)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+// rejects every request the way DataPoint does an unknown key
+type rejectingSource struct{}
+
+func (rejectingSource) Get(ctx context.Context, url string) ([]byte, error) {
+	return nil, &data.StatusError{Code: 403}
+}
+
+func TestEnterApiKey(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	defer func(key string) { apiKey = key }(apiKey)
+
+	m := askForApiKey(initialModel(defaultConfig()))
+	if view := m.View(); !strings.Contains(view, "API key") {
+		t.Fatalf("expected the key entry screen, got:\n%s", view)
+	}
+
+	var cmd tea.Cmd
+	update := func(msg tea.Msg) {
+		var next tea.Model
+		next, cmd = m.Update(msg)
+		m = next.(model)
+	}
+
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("secret")})
+	if view := m.View(); strings.Contains(view, "secret") {
+		t.Errorf("expected the key to be masked, got:\n%s", view)
+	}
+
+	m.fetcher = rejectingSource{}
+	update(tea.KeyMsg{Type: tea.KeyEnter})
+	update(cmd())
+	if !m.enteringKey || !strings.Contains(m.notice, "wasn't accepted") {
+		t.Fatalf("expected the key to be rejected, got %q", m.notice)
+	}
+
+	m.fetcher = data.Offline{}
+	update(tea.KeyMsg{Type: tea.KeyEnter})
+	update(cmd())
+	if m.enteringKey || !m.loadingSites || apiKey != "secret" {
+		t.Fatal("expected an accepted key to move on to loading the sitelist")
+	}
+
+	if cfg, err := loadConfig(); err != nil || cfg.APIKey != "secret" {
+		t.Errorf("expected the key to be saved, got %q and %v", cfg.APIKey, err)
+	}
+
+	t.Setenv(apiKeyEnv, "from env")
+	if keys := getApiKeys(Config{APIKey: "secret"}); len(keys) != 1 || keys[0] != "from env" {
+		t.Errorf("expected the env var to take precedence, got %q", keys)
+	}
+}
+
+func TestGetApiKeys(t *testing.T) {
+	t.Setenv(apiKeyEnv, " first, second,,third ")
+	if keys := getApiKeys(Config{}); strings.Join(keys, "|") != "first|second|third" {
+		t.Errorf("expected the comma separated keys, got %q", keys)
+	}
+
+	os.Unsetenv(apiKeyEnv)
+	if keys := getApiKeys(Config{APIKey: "first", APIKeys: []string{"second"}}); strings.Join(keys, "|") != "first|second" {
+		t.Errorf("expected the configured keys in order, got %q", keys)
+	}
+}
+
+func TestDescribeKeysExhausted(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &data.KeysExhaustedError{Until: time.Date(2024, 1, 10, 14, 30, 0, 0, time.Local)})
+	if message := describeError(err); !strings.Contains(message, "14:30") {
+		t.Errorf("expected the reset time in %q", message)
+	}
+}
+
+func TestQuitFromApiKeyEntry(t *testing.T) {
+	m := askForApiKey(initialModel(defaultConfig()))
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if cmd == nil {
+		t.Fatal("expected esc to quit")
+	}
+
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("expected esc to quit")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+// a forecast run lasts hours, so the timesteps only need checking now
+// and then
+const capabilitiesCacheTime = 5 * time.Minute
+
+// returned rather than requesting a forecast the API has nothing for,
+// as happens for a while after it's updated
+var errNoTimesteps = errors.New("the Met Office has no forecasts available right now, try again shortly")
+
+type cachedCapabilities struct {
+	timesteps []string
+	// when the latest run was issued
+	dataDate time.Time
+	fetched  time.Time
+}
+
+// shared by the commands fetching forecasts, which run concurrently
+var capabilitiesCache = struct {
+	sync.Mutex
+	entries map[resolution]cachedCapabilities
+}{entries: make(map[resolution]cachedCapabilities)}
+
+// the timesteps the API has forecasts for at a resolution, e.g.
+// "2024-01-10T09:00:00Z", fetched again once the cached ones are stale
+func (m model) getCapabilities(ctx context.Context, res resolution) ([]string, error) {
+	capabilitiesCache.Lock()
+	cached, ok := capabilitiesCache.entries[res]
+	capabilitiesCache.Unlock()
+
+	if ok && time.Since(cached.fetched) < capabilitiesCacheTime {
+		data.DefaultClient.Metrics.CacheHit()
+		return cached.timesteps, nil
+	}
+	data.DefaultClient.Metrics.CacheMiss()
+
+	url := makeUrl("val/wxfcs/all/json/capabilities", "res="+string(res))
+
+	body, err := m.fetcher.Get(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch capabilities: %w", err)
+	}
+
+	var capabilities data.Capabilities
+	if err := json.Unmarshal(body, &capabilities); err != nil {
+		return nil, fmt.Errorf("error decoding JSON: %w", err)
+	}
+
+	cached = cachedCapabilities{timesteps: capabilities.Resource.TimeSteps.TS, fetched: time.Now()}
+	if date, err := parseDataDate(capabilities.Resource.DataDate); err == nil {
+		cached.dataDate = date
+	}
+
+	capabilitiesCache.Lock()
+	capabilitiesCache.entries[res] = cached
+	capabilitiesCache.Unlock()
+
+	return cached.timesteps, nil
+}
+
+// when the latest forecasts at a resolution were issued, as of the
+// last time the capabilities were fetched
+func latestDataDate(res resolution) (time.Time, bool) {
+	capabilitiesCache.Lock()
+	defer capabilitiesCache.Unlock()
+
+	cached, ok := capabilitiesCache.entries[res]
+
+	return cached.dataDate, ok && !cached.dataDate.IsZero()
+}
+
+func clearCapabilities() {
+	capabilitiesCache.Lock()
+	defer capabilitiesCache.Unlock()
+
+	capabilitiesCache.entries = make(map[resolution]cachedCapabilities)
+}
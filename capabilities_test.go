@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestGetCapabilities(t *testing.T) {
+	defer clearCapabilities()
+	clearCapabilities()
+
+	requests := 0
+	m := model{fetcher: data.SourceFunc(func(ctx context.Context, url string) ([]byte, error) {
+		if strings.Contains(url, "capabilities") {
+			requests++
+		}
+		return data.Offline{}.Get(ctx, url)
+	})}
+
+	timesteps, err := m.getCapabilities(context.Background(), threeHourlyResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timesteps) != 40 || timesteps[0] != "2024-01-10T00:00:00Z" || timesteps[39] != "2024-01-14T21:00:00Z" {
+		t.Errorf("expected 40 3hourly timesteps from the 10th to the 14th, got %v", timesteps)
+	}
+
+	timesteps, err = m.getCapabilities(context.Background(), dailyResolution)
+	if err != nil || len(timesteps) != 10 || timesteps[1] != "2024-01-10T12:00:00Z" {
+		t.Errorf("expected 10 daily timesteps, got %v and %v", timesteps, err)
+	}
+
+	latest, ok := latestDataDate(threeHourlyResolution)
+	if !ok || !latest.Equal(time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the run issued at 09:00, got %v", latest)
+	}
+
+	// they're cached for a while
+	m.getCapabilities(context.Background(), threeHourlyResolution)
+	if requests != 2 {
+		t.Errorf("expected one request for each resolution, got %d", requests)
+	}
+}
+
+func TestNoTimesteps(t *testing.T) {
+	defer clearCapabilities()
+	clearCapabilities()
+
+	forecasts := 0
+	stubbed := model{fetcher: data.SourceFunc(func(ctx context.Context, url string) ([]byte, error) {
+		if strings.Contains(url, "capabilities") {
+			return []byte(`{"Resource": {"dataDate": "2024-01-10T12:00:00Z", "res": "daily", "TimeSteps": {"TS": []}}}`), nil
+		}
+		forecasts++
+		return data.Offline{}.Get(ctx, url)
+	})}
+
+	_, err := stubbed.getSiteData(context.Background(), "310002", dailyResolution)
+	if !errors.Is(err, errNoTimesteps) || forecasts != 0 {
+		t.Errorf("expected no forecast to be requested, got %v after %d requests", err, forecasts)
+	}
+
+	// a forecast fetched before the latest run says there's newer data
+	siteData, err := model{fetcher: data.Offline{}}.getSiteData(context.Background(), "310002", threeHourlyResolution)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := model{siteData: siteData, forecastResolution: dailyResolution}
+	if view := issuedView(m); !strings.Contains(view, "newer data out at") {
+		t.Errorf("expected the newer run to be pointed out, got %q", view)
+	}
+
+	m.forecastResolution = threeHourlyResolution
+	if view := issuedView(m); strings.Contains(view, "newer data") {
+		t.Errorf("expected nothing newer than the latest run, got %q", view)
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// lines of the carousel that aren't slot rows, the title, position,
+// summary, table border and header, footer, hint and status bar
+const carouselChrome = 14
+
+// one page of the carousel, the periods are those dated that day
+type carouselDay struct {
+	date    time.Time
+	periods []int
+}
+
+// group the periods of the forecast being viewed by their date, up to
+// the number of days being listed
+func carouselDays(m model) []carouselDay {
+	var days []carouselDay
+
+	for i, period := range m.siteData.Site.Info.Location.Periods {
+		date, err := parsePeriodDate(period.Date)
+		if err != nil || len(period.Forecasts) == 0 || !m.dateFilter.includes(date, clock()) {
+			continue
+		}
+
+		if n := len(days); n > 0 && days[n-1].date.Equal(date) {
+			days[n-1].periods = append(days[n-1].periods, i)
+			continue
+		}
+
+		if m.maxDays > 0 && len(days) >= m.maxDays {
+			break
+		}
+
+		days = append(days, carouselDay{date: date, periods: []int{i}})
+	}
+
+	return days
+}
+
+// the page showing date, or the next one after it, so switching
+// resolution stays on the same day where it can
+func carouselIndex(days []carouselDay, date time.Time) int {
+	for i, day := range days {
+		if !day.date.Before(date) {
+			return i
+		}
+	}
+
+	return max(0, len(days)-1)
+}
+
+// switch between the list and the carousel, each opening on the day
+// the other was showing
+func toggleCarousel(m model) model {
+	if !m.carousel {
+		m.carousel = true
+		if item, ok := m.list.SelectedItem().(forecastItem); ok {
+			periodIndex, _ := item.Position()
+			date, err := parsePeriodDate(m.siteData.Site.Info.Location.Periods[periodIndex].Date)
+			if err == nil {
+				m.carouselDate = date
+			}
+		}
+
+		return m
+	}
+
+	m.carousel = false
+	periods := m.siteData.Site.Info.Location.Periods
+	for i, item := range m.list.Items() {
+		periodIndex, _ := item.(forecastItem).Position()
+		if date, err := parsePeriodDate(periods[periodIndex].Date); err == nil && !date.Before(m.carouselDate) {
+			m.list.Select(i)
+			break
+		}
+	}
+
+	return m
+}
+
+func stepCarousel(m model, step int) model {
+	days := carouselDays(m)
+	if len(days) == 0 {
+		return m
+	}
+
+	i := max(0, min(len(days)-1, carouselIndex(days, m.carouselDate)+step))
+	m.carouselDate = days[i].date
+
+	return m
+}
+
+func updateCarousel(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch key.String() {
+	case "left", "h":
+		return stepCarousel(m, -1), nil
+	case "right", "l":
+		return stepCarousel(m, 1), nil
+	case "v":
+		return toggleCarousel(m), nil
+	// the location's keys that don't move around the list
+	case "r", "R", "o", "+", "-", "w", "n", "p", "x", "esc", "0", "1", "2", "3":
+		return updateLocation(msg, m)
+	}
+
+	return m, nil
+}
+
+// e.g. "High 9°C / Low 5°C · 🌧️ Light rain · 60% chance of rain"
+func carouselSummary(m model, date time.Time) string {
+	for _, summary := range summariseDays(m.siteData) {
+		if !summary.date.Equal(date) {
+			continue
+		}
+
+		var parts []string
+		if highLow := formatHighLow(summary.high, summary.low, m.tempUnit); highLow != "" {
+			parts = append(parts, highLow)
+		}
+		if summary.weather != "" {
+			parts = append(parts, weatherIcon(summary.weather)+" "+describeCode(summary.weather, m.language))
+		}
+		if percent, err := strconv.Atoi(summary.rain); err == nil {
+			parts = append(parts, describeRain(percent))
+		}
+
+		return strings.Join(parts, " · ")
+	}
+
+	return ""
+}
+
+func carouselView(m model) string {
+	days := carouselDays(m)
+	if len(days) == 0 {
+		return listStyle.Render(wrapText(m, "No forecast data available for this location\n\nPress esc to go back to the search or q to quit"))
+	}
+
+	index := carouselIndex(days, m.carouselDate)
+	day := days[index]
+
+	title := m.siteData.Site.Info.Location.Name + " - " + day.date.Format("Mon, 02 Jan 2006")
+	if m.observing {
+		title += " (observed)"
+	}
+
+	hintStyle := lipgloss.NewStyle().Foreground(paletteColor(grey))
+	position := hintStyle.Render(fmt.Sprintf("Day %d of %d", index+1, len(days)))
+
+	var rows []table.Row
+	for _, periodIndex := range day.periods {
+		rows = append(rows, dayRows(m, periodIndex)...)
+	}
+
+	t := table.New(
+		table.WithColumns(dayColumns()),
+		table.WithRows(rows),
+		table.WithHeight(max(1, min(len(rows), m.height-carouselChrome))),
+		table.WithFocused(false),
+	)
+	t.SetStyles(tableStyle)
+
+	header := title + "\n" + position + "  " + issuedView(m)
+	if banner := warningsBanner(m); banner != "" {
+		header = banner + "\n" + header
+	}
+
+	text := header + "\n" + carouselSummary(m, day.date) + "\n" + borderStyle.Render(t.View())
+	if footer := footerView(m); footer != "" {
+		text += "\n" + footer
+	}
+	text += "\n" + hintStyle.Render("←/→ to change day, v for the list, esc for the search, q to quit")
+
+	return listStyle.Render(text + "\n" + statusBar(m))
+}
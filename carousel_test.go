@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestCarousel(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Resolution = string(threeHourlyResolution)
+	m := startedModel(cfg, data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	m = next.(model)
+
+	m = runCmd(chooseLocation(m, "310002"))
+	days := len(carouselDays(m))
+
+	// open on the day selected in the list
+	m.list.Select(len(m.siteData.Site.Info.Location.Periods[0].Forecasts) + 2)
+
+	press := func(key string) {
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = runCmd(next.(model), cmd)
+	}
+
+	press("v")
+	view := m.View()
+	for _, want := range []string{"Thu, 11 Jan 2024", "Day 2 of", "High", "06:00", "21:00"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the carousel:\n%s", want, view)
+		}
+	}
+
+	press("l")
+	press("l")
+	if !strings.Contains(m.View(), "Day 4 of") {
+		t.Errorf("expected to move on two days, got:\n%s", m.View())
+	}
+	for i := 0; i < days; i++ {
+		press("h")
+	}
+	if !strings.Contains(m.View(), "Day 1 of") {
+		t.Errorf("expected to stop at the first day, got:\n%s", m.View())
+	}
+
+	// switching to daily forecasts stays on the same day
+	press("l")
+	press("r")
+	if m.forecastResolution != dailyResolution || !strings.Contains(m.View(), "Thu, 11 Jan 2024") || !strings.Contains(m.View(), "Night") {
+		t.Errorf("expected the daily forecasts for the same day, got:\n%s", m.View())
+	}
+
+	// and the list picks up from there
+	press("v")
+	if item := m.list.SelectedItem().(forecastItem); m.carousel || !strings.HasPrefix(item.Title(), "Thu, 11 Jan 2024") {
+		t.Errorf("expected the list on the carousel's day, got %q", item.Title())
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// forecasts for days longer ago than this are forgotten
+const seenRetention = 48 * time.Hour
+
+// a forecast as it was when last opened, Date is its period's
+type seenForecast struct {
+	Date time.Time    `json:"date"`
+	Data forecastData `json:"data"`
+}
+
+// kept with the log, as it's only worth having to compare against
+func defaultSeenPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "forecast", "seen.json")
+}
+
+// e.g. "3772/3hourly/2024-01-10/540"
+func seenKey(locationId string, res resolution, date time.Time, slot string) string {
+	return locationId + "/" + string(res) + "/" + date.Format(time.DateOnly) + "/" + slot
+}
+
+func loadSeen(path string) (map[string]seenForecast, error) {
+	seen := make(map[string]seenForecast)
+
+	body, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return seen, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read seen forecasts: %w", err)
+	}
+
+	if err := json.Unmarshal(body, &seen); err != nil {
+		return nil, fmt.Errorf("error decoding seen forecasts %s: %w", path, err)
+	}
+
+	return seen, nil
+}
+
+// write the seen forecasts to path, dropping those for days long gone
+func saveSeen(path string, seen map[string]seenForecast) error {
+	for key, forecast := range seen {
+		if clock().Sub(forecast.Date) > seenRetention {
+			delete(seen, key)
+		}
+	}
+
+	body, err := json.Marshal(seen)
+	if err != nil {
+		return fmt.Errorf("error encoding seen forecasts: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err == nil {
+		err = os.WriteFile(path, body, 0o644)
+	}
+	if err != nil {
+		return fmt.Errorf("could not write seen forecasts: %w", err)
+	}
+
+	return nil
+}
+
+// the forecast as it was when last opened, nil if it hadn't been
+type seenMsg struct {
+	key    string
+	before *forecastData
+}
+
+// the commands checking forecasts can overlap as the user steps
+// through them, each reads and writes the whole file
+var seenMu sync.Mutex
+
+// look up how the forecast being opened looked the last time it was,
+// and remember it for next time, off the UI's goroutine. Observations
+// don't change once they're made, so there's nothing to compare
+func checkChanges(m model, date time.Time, slot string) (model, tea.Cmd) {
+	m.seenBefore, m.changesKey = nil, ""
+	if m.seenPath == "" || m.observing {
+		return m, nil
+	}
+
+	key := seenKey(m.locationId, m.forecastResolution, date, slot)
+	m.changesKey = key
+	path, forecast := m.seenPath, m.forecastData
+
+	return m, func() tea.Msg {
+		seenMu.Lock()
+		defer seenMu.Unlock()
+
+		seen, err := loadSeen(path)
+		if err != nil {
+			slog.Warn("could not load seen forecasts", "err", err)
+			return nil
+		}
+
+		msg := seenMsg{key: key}
+		if previous, ok := seen[key]; ok {
+			msg.before = &previous.Data
+		}
+
+		seen[key] = seenForecast{Date: date, Data: forecast}
+		if err := saveSeen(path, seen); err != nil {
+			slog.Warn("could not save seen forecasts", "err", err)
+		}
+
+		return msg
+	}
+}
+
+// how a forecast has changed, e.g. "temp ↑2°", in the units it's shown
+// in and leaving out anything that's the same or unknown either time
+func diffForecasts(before, after forecastData, tempUnit temperatureUnit, windUnit windUnit) []string {
+	// the number a value is shown as, e.g. 48 for "48°F"
+	shown := func(formatted string) (int, error) {
+		var n int
+		_, err := fmt.Sscanf(formatted, "%d", &n)
+		return n, err
+	}
+
+	fields := []struct {
+		label string
+		unit  string
+		value func(forecastData) (int, error)
+	}{
+		{"temp", "°", func(f forecastData) (int, error) { return shown(formatTemp(f.Temperature, tempUnit)) }},
+		{"feels like", "°", func(f forecastData) (int, error) { return shown(formatTemp(f.FeelsLikeTemp, tempUnit)) }},
+		{"chance of rain", "%", forecastData.PrecipitationPct},
+		{"wind", string(windUnit), func(f forecastData) (int, error) { return shown(formatWind(f.WindSpeed, windUnit)) }},
+		{"gusts", string(windUnit), func(f forecastData) (int, error) { return shown(formatWind(f.GustSpeed, windUnit)) }},
+		{"humidity", "%", forecastData.HumidityPct},
+		{"UV", "", forecastData.UVIndex},
+	}
+
+	var changes []string
+	for _, field := range fields {
+		was, errBefore := field.value(before)
+		is, errAfter := field.value(after)
+		if errBefore != nil || errAfter != nil || was == is {
+			continue
+		}
+
+		arrow := "↑"
+		if is < was {
+			arrow = "↓"
+		}
+
+		changes = append(changes, field.label+" "+arrow+strconv.Itoa(max(is-was, was-is))+field.unit)
+	}
+
+	return changes
+}
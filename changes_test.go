@@ -0,0 +1,133 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestDiffForecasts(t *testing.T) {
+	before := forecastData{
+		Temperature: "12", FeelsLikeTemp: "10", Precipitation: "40",
+		WindSpeed: "10", GustSpeed: "25", Humidity: "70", UV: "2",
+	}
+
+	if changes := diffForecasts(before, before, celsiusUnit, mphUnit); len(changes) != 0 {
+		t.Errorf("expected no changes, got %q", changes)
+	}
+
+	tests := []struct {
+		change func(*forecastData)
+		want   string
+	}{
+		{func(f *forecastData) { f.Temperature = "14" }, "temp ↑2°"},
+		{func(f *forecastData) { f.Temperature = "9" }, "temp ↓3°"},
+		{func(f *forecastData) { f.FeelsLikeTemp = "11" }, "feels like ↑1°"},
+		{func(f *forecastData) { f.FeelsLikeTemp = "8" }, "feels like ↓2°"},
+		{func(f *forecastData) { f.Precipitation = "60" }, "chance of rain ↑20%"},
+		{func(f *forecastData) { f.Precipitation = "30" }, "chance of rain ↓10%"},
+		{func(f *forecastData) { f.WindSpeed = "15" }, "wind ↑5mph"},
+		{func(f *forecastData) { f.WindSpeed = "8" }, "wind ↓2mph"},
+		{func(f *forecastData) { f.GustSpeed = "40" }, "gusts ↑15mph"},
+		{func(f *forecastData) { f.GustSpeed = "20" }, "gusts ↓5mph"},
+		{func(f *forecastData) { f.Humidity = "75" }, "humidity ↑5%"},
+		{func(f *forecastData) { f.Humidity = "60" }, "humidity ↓10%"},
+		{func(f *forecastData) { f.UV = "4" }, "UV ↑2"},
+		{func(f *forecastData) { f.UV = "1" }, "UV ↓1"},
+		// a value that's gone missing isn't a change
+		{func(f *forecastData) { f.Precipitation = missingValue }, ""},
+	}
+
+	for _, test := range tests {
+		after := before
+		test.change(&after)
+
+		var want []string
+		if test.want != "" {
+			want = []string{test.want}
+		}
+		if got := diffForecasts(before, after, celsiusUnit, mphUnit); !reflect.DeepEqual(got, want) {
+			t.Errorf("got %q, want %q", got, want)
+		}
+	}
+
+	after := before
+	after.Temperature, after.Precipitation = "14", "30"
+	if got := strings.Join(diffForecasts(before, after, celsiusUnit, mphUnit), ", "); got != "temp ↑2°, chance of rain ↓10%" {
+		t.Errorf("expected both changes in order, got %q", got)
+	}
+
+	// the changes are in the units the forecast is shown in
+	after = before
+	after.Temperature, after.WindSpeed = "14", "15"
+	if got := strings.Join(diffForecasts(before, after, fahrenheitUnit, kphUnit), ", "); got != "temp ↑3°, wind ↑8km/h" {
+		t.Errorf("expected the changes in °F and km/h, got %q", got)
+	}
+}
+
+func TestCheckChanges(t *testing.T) {
+	defer func(c func() time.Time) { clock = c }(clock)
+	clock = func() time.Time { return time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC) }
+
+	m := runCmd(chooseLocation(startedModel(defaultConfig(), data.Offline{}), "310002"))
+	m.seenPath = filepath.Join(t.TempDir(), "seen.json")
+	m.list.Select(2)
+
+	// nothing to compare with the first time
+	m = runCmd(openForecast(m))
+	if m.seenBefore != nil || strings.Contains(forecastView(m), "Since last check") {
+		t.Fatalf("expected no changes without a snapshot, got %+v", m.seenBefore)
+	}
+
+	// the same forecast again hasn't changed
+	m = runCmd(openForecast(m))
+	if m.seenBefore == nil || strings.Contains(forecastView(m), "Since last check") {
+		t.Errorf("expected the snapshot without any changes, got %+v", m.seenBefore)
+	}
+
+	// a newer run forecasting it colder
+	seen, err := loadSeen(m.seenPath)
+	if err != nil || len(seen) != 1 {
+		t.Fatalf("expected one seen forecast, got %d and %v", len(seen), err)
+	}
+	for key, forecast := range seen {
+		temp, _ := forecast.Data.TemperatureC()
+		forecast.Data.Temperature = strconv.Itoa(temp + 3)
+		seen[key] = forecast
+	}
+	if err := saveSeen(m.seenPath, seen); err != nil {
+		t.Fatal(err)
+	}
+
+	m = runCmd(openForecast(m))
+	if view := forecastView(m); !strings.Contains(view, "Since last check: temp ↓3°") {
+		t.Errorf("expected the drop in temperature, got:\n%s", view)
+	}
+
+	// a check finishing after the user moved on isn't shown
+	stale := seenMsg{key: m.changesKey, before: m.seenBefore}
+	m, _ = stepForecast(m, 1)
+	if next, _ := m.Update(stale); next.(model).seenBefore != nil {
+		t.Error("expected the previous forecast's changes to be ignored")
+	}
+
+	// days long gone are forgotten
+	clock = func() time.Time { return time.Date(2024, 1, 20, 12, 0, 0, 0, time.UTC) }
+	if err := saveSeen(m.seenPath, seen); err != nil {
+		t.Fatal(err)
+	}
+	if seen, _ := loadSeen(m.seenPath); len(seen) != 0 {
+		t.Errorf("expected old forecasts to be dropped, got %d", len(seen))
+	}
+
+	// there's nothing remembered without a path
+	m.seenPath = ""
+	if m, cmd := checkChanges(m, clock(), "Day"); cmd != nil || m.seenBefore != nil {
+		t.Error("expected nothing to be looked up")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// swapped out in tests so they don't touch the real clipboard
+var writeClipboard = clipboard.WriteAll
+
+type copiedMsg struct {
+	err error
+}
+
+// the forecast being viewed as plain text, for sharing
+func forecastText(m model) string {
+	fd := m.forecastData
+	period := m.list.SelectedItem().(forecastItem).Title()
+
+	lines := []string{
+		m.siteData.Site.Info.Location.Name + " - " + period,
+		describeCode(fd.WeatherCode, m.language),
+	}
+
+	temp := "Temperature " + formatTemp(fd.Temperature, m.tempUnit)
+	if fd.FeelsLikeTemp != "" {
+		temp += ", feels like " + formatTemp(fd.FeelsLikeTemp, m.tempUnit)
+	}
+	lines = append(lines, temp)
+
+	wind := "Wind " + fd.WindDirection + " " + formatWind(fd.WindSpeed, m.windUnit)
+	if _, err := fd.GustSpeedMph(); err == nil {
+		wind += ", gusts up to " + formatWind(fd.GustSpeed, m.windUnit)
+	}
+	lines = append(lines, wind)
+
+	if rain, err := fd.PrecipitationPct(); err == nil {
+		lines = append(lines, describeRain(rain))
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func copyForecast(m model) tea.Cmd {
+	text := forecastText(m)
+
+	return func() tea.Msg {
+		return copiedMsg{err: writeClipboard(text)}
+	}
+}
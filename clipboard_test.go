@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestCopyForecast(t *testing.T) {
+	defer func(write func(string) error) { writeClipboard = write }(writeClipboard)
+
+	var copied string
+	writeClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+
+	m := startedModel(defaultConfig(), data.Offline{})
+	m = runCmd(chooseLocation(m, "310002"))
+	m, _ = openForecast(m)
+
+	press := func() {
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+		next, _ = next.Update(cmd())
+		m = next.(model)
+	}
+
+	press()
+	if m.notice != "Copied!" {
+		t.Errorf("expected a confirmation, got %q", m.notice)
+	}
+
+	for _, want := range []string{"LEEDS - ", "Temperature ", "Wind ", "chance of rain"} {
+		if !strings.Contains(copied, want) {
+			t.Errorf("expected %q in the copied text, got:\n%s", want, copied)
+		}
+	}
+
+	if strings.Contains(copied, "\x1b") {
+		t.Errorf("expected plain text, got %q", copied)
+	}
+
+	writeClipboard = func(string) error { return errors.New("no clipboard utilities available") }
+	press()
+	if !strings.Contains(m.notice, "no clipboard") {
+		t.Errorf("expected an explanation without a clipboard, got %q", m.notice)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+const (
+	compareLabelWidth = 18
+	// lines taken by the title, column headers and hint
+	compareChrome = 5
+)
+
+// one time period in a comparison, either side may not have a forecast
+// for it
+type compareSlot struct {
+	date, time  string
+	left, right *data.Forecast
+}
+
+// daily periods sort day before night, the rest are minutes past midnight
+func slotOrder(slotTime string) int {
+	switch slotTime {
+	case "Day":
+		return 0
+	case "Night":
+		return 1
+	default:
+		minutes, _ := strconv.Atoi(slotTime)
+		return minutes
+	}
+}
+
+// match up the forecasts of two sites by date and time
+func alignForecasts(left, right data.SiteData) []compareSlot {
+	var slots []compareSlot
+	index := make(map[[2]string]int)
+
+	add := func(siteData data.SiteData, isLeft bool) {
+		for _, period := range siteData.Site.Info.Location.Periods {
+			for i := range period.Forecasts {
+				f := &period.Forecasts[i]
+				key := [2]string{period.Date, f.Time}
+
+				n, ok := index[key]
+				if !ok {
+					n = len(slots)
+					index[key] = n
+					slots = append(slots, compareSlot{date: period.Date, time: f.Time})
+				}
+
+				if isLeft {
+					slots[n].left = f
+				} else {
+					slots[n].right = f
+				}
+			}
+		}
+	}
+
+	add(left, true)
+	add(right, false)
+
+	sort.SliceStable(slots, func(i, j int) bool {
+		if slots[i].date != slots[j].date {
+			return slots[i].date < slots[j].date
+		}
+
+		return slotOrder(slots[i].time) < slotOrder(slots[j].time)
+	})
+
+	return slots
+}
+
+// remember the location being viewed and go and pick another to compare
+func pinLocation(m model) model {
+	m.compareId = m.locationId
+	m.compareData = m.siteData
+	m.compareResolution = m.forecastResolution
+
+	m = returnToSearch(m)
+	m.notice = "Pinned " + m.compareData.Site.Info.Location.Name + ", choose a location to compare"
+
+	return m
+}
+
+// compare the location just opened with the pinned one, if there is
+// one. Observations, or forecasts at another resolution, have slots
+// that don't line up with the pinned forecasts so aren't compared
+func startComparing(m model) model {
+	m.comparing = false
+	if m.compareId == "" || m.compareId == m.locationId {
+		return m
+	}
+
+	if m.observing || m.forecastResolution != m.compareResolution {
+		m.notice = "Can't compare these with the " + string(m.compareResolution) + " forecasts pinned for " + m.compareData.Site.Info.Location.Name
+
+		return m
+	}
+
+	m.comparing = true
+
+	return m
+}
+
+func clearComparison(m model) model {
+	m.compareId = ""
+	m.compareData = data.SiteData{}
+	m.comparing = false
+	m.compareOffset = 0
+
+	return m
+}
+
+func updateCompare(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		slots := len(alignForecasts(m.compareData, m.siteData))
+
+		switch msg.String() {
+		case "up", "k":
+			m.compareOffset = max(0, m.compareOffset-1)
+		case "down", "j":
+			m.compareOffset = max(0, min(slots-visibleCompareSlots(m.height), m.compareOffset+1))
+		case "x":
+			m = clearComparison(m)
+		case "esc":
+			m.comparing = false
+		}
+	}
+
+	return m, nil
+}
+
+func visibleCompareSlots(height int) int {
+	_, v := listStyle.GetFrameSize()
+	return max(1, height-v-compareChrome)
+}
+
+func slotLabel(slot compareSlot, twelveHour bool) string {
+	date, err := parsePeriodDate(slot.date)
+	if err != nil {
+		return missingValue + " " + slot.time
+	}
+
+	if t, ok := localSlotTime(date, slot.time); ok {
+		return t.Format("Mon 02 Jan") + " " + clockTime(t, twelveHour)
+	}
+
+	return date.Format("Mon 02 Jan") + " " + slot.time
+}
+
+func compareCell(m model, res resolution, meta data.Meta, f *data.Forecast) string {
+	if f == nil {
+		return missingValue
+	}
+
+	fd := flattenForecast(res, meta, *f)
+	cell := weatherIcon(fd.WeatherCode) + " " + renderTemp(fd.Temperature, m.tempUnit) + " " + formatWind(fd.WindSpeed, m.windUnit)
+	// the chance of rain, the forecasts don't say how much
+	if rain, err := fd.PrecipitationPct(); err == nil {
+		cell += " " + compactRain(rain)
+	}
+
+	return cell
+}
+
+func compareView(m model) string {
+	leftName := m.compareData.Site.Info.Location.Name
+	rightName := m.siteData.Site.Info.Location.Name
+	title := leftName + " vs " + rightName
+
+	slots := alignForecasts(m.compareData, m.siteData)
+	if len(slots) == 0 {
+		return listStyle.Render(title + "\n\nNo forecast data available to compare")
+	}
+
+	h, _ := listStyle.GetFrameSize()
+	columnWidth := max(1, (m.width-h-compareLabelWidth)/2)
+
+	bold := lipgloss.NewStyle().Bold(true)
+	labels := []string{""}
+	left := []string{bold.Render(leftName)}
+	right := []string{bold.Render(rightName)}
+
+	offset := min(m.compareOffset, len(slots)-1)
+	end := min(len(slots), offset+visibleCompareSlots(m.height))
+	for _, slot := range slots[offset:end] {
+		labels = append(labels, slotLabel(slot, m.twelveHour))
+		left = append(left, compareCell(m, m.compareResolution, m.compareData.Site.MetaInfo, slot.left))
+		right = append(right, compareCell(m, m.forecastResolution, m.siteData.Site.MetaInfo, slot.right))
+	}
+
+	column := func(lines []string, width int) string {
+		return lipgloss.NewStyle().Width(width).MaxWidth(width).Render(strings.Join(lines, "\n"))
+	}
+
+	table := lipgloss.JoinHorizontal(lipgloss.Top,
+		column(labels, compareLabelWidth),
+		column(left, columnWidth),
+		column(right, columnWidth),
+	)
+
+	hint := lipgloss.NewStyle().
+		Foreground(paletteColor(grey)).
+		Render("☂ chance of rain, ↑/↓ to scroll, x to stop comparing, esc to go back, q to quit")
+
+	return listStyle.Render(title + "\n\n" + table + "\n\n" + hint)
+}
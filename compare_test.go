@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func siteWith(periods ...data.Period) data.SiteData {
+	var siteData data.SiteData
+	siteData.Site.Info.Location.Periods = periods
+	return siteData
+}
+
+func TestAlignForecasts(t *testing.T) {
+	left := siteWith(
+		data.Period{Date: "2024-01-10Z", Forecasts: []data.Forecast{{Time: "Night"}}},
+		data.Period{Date: "2024-01-11Z", Forecasts: []data.Forecast{{Time: "Day"}, {Time: "Night"}}},
+	)
+	right := siteWith(
+		data.Period{Date: "2024-01-10Z", Forecasts: []data.Forecast{{Time: "Day"}, {Time: "Night"}}},
+		data.Period{Date: "2024-01-11Z", Forecasts: []data.Forecast{{Time: "Day"}}},
+	)
+
+	slots := alignForecasts(left, right)
+
+	want := []struct {
+		date, time  string
+		left, right bool
+	}{
+		{"2024-01-10Z", "Day", false, true},
+		{"2024-01-10Z", "Night", true, true},
+		{"2024-01-11Z", "Day", true, true},
+		{"2024-01-11Z", "Night", true, false},
+	}
+
+	if len(slots) != len(want) {
+		t.Fatalf("expected %d slots, got %d", len(want), len(slots))
+	}
+
+	for i, w := range want {
+		slot := slots[i]
+		if slot.date != w.date || slot.time != w.time || (slot.left != nil) != w.left || (slot.right != nil) != w.right {
+			t.Errorf("slot %d: got %+v, want %+v", i, slot, w)
+		}
+	}
+
+	// 3hourly slots are ordered by time of day rather than as text
+	hourly := alignForecasts(siteWith(data.Period{Date: "2024-01-10Z", Forecasts: []data.Forecast{{Time: "1260"}, {Time: "180"}}}), data.SiteData{})
+	if hourly[0].time != "180" {
+		t.Errorf("expected 03:00 before 21:00, got %s first", hourly[0].time)
+	}
+}
+
+func TestCompareLocations(t *testing.T) {
+	m := startedModel(defaultConfig(), data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	m = next.(model)
+
+	open := func(id string) {
+		m = runCmd(chooseLocation(m, id))
+	}
+
+	open("310002")
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = next.(model)
+
+	if m.locationChosen || m.compareId != "310002" {
+		t.Fatal("pinning should go back to the search")
+	}
+
+	open("3772")
+	if !m.comparing {
+		t.Fatal("picking a second location should compare them")
+	}
+
+	// every offline site shares the same sample forecast
+	view := compareView(m)
+	if !strings.Contains(view, "LEEDS vs LEEDS") || !strings.Contains(view, "Wed 10 Jan Day") {
+		t.Errorf("unexpected comparison:\n%s", view)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m = next.(model)
+	if m.comparing || m.compareId != "" || !m.locationChosen {
+		t.Error("x should stop comparing and leave the second location open")
+	}
+}
+
+func TestCompareMixedResolutions(t *testing.T) {
+	m := startedModel(defaultConfig(), data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	m = next.(model)
+
+	// pin a site's daily forecasts
+	m.forecastResolution = dailyResolution
+	m = runCmd(chooseLocation(m, "310002"))
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = next.(model)
+
+	// the second is fetched daily too, whatever was last viewed
+	m.forecastResolution = threeHourlyResolution
+	m = runCmd(chooseLocation(m, "3772"))
+	if !m.comparing || m.forecastResolution != dailyResolution {
+		t.Fatalf("expected the daily forecasts to be compared, got %s", m.forecastResolution)
+	}
+	if view := compareView(m); !strings.Contains(view, "Wed 10 Jan Day") {
+		t.Errorf("expected the daily slots to line up, got:\n%s", view)
+	}
+
+	// forecasts that don't line up aren't compared
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(model)
+	m, _ = handleSiteData(m, siteDataMsg{id: m.sessionId, reason: fetchSelect, siteData: m.siteData, resolution: threeHourlyResolution})
+	if m.comparing || !strings.Contains(m.notice, "Can't compare") {
+		t.Errorf("expected a notice instead of the comparison, got %q", m.notice)
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+// Config holds the settings remembered between runs, flags given on the
+// command line take precedence over it
+type Config struct {
+	// daily or 3hourly
+	Resolution string `json:"resolution"`
+	// C or F
+	TemperatureUnit string `json:"temperatureUnit"`
+	// mph, km/h or kt
+	WindUnit string `json:"windUnit"`
+	Theme    string `json:"theme"`
+	// a site id to open straight away, if any, set as home with h
+	Location string `json:"location"`
+	// how many days of forecasts to list, 0 for all
+	Days int `json:"days"`
+	// site ids shown on the dashboard at launch, added with f
+	Favourites []string `json:"favourites,omitempty"`
+	// for weather descriptions, en or cy, empty for English
+	Language string `json:"language,omitempty"`
+	// show times like 3:00pm rather than 15:00
+	TwelveHour bool `json:"twelveHour"`
+	// used when MET_OFFICE_API_KEY isn't set
+	APIKey string `json:"apiKey,omitempty"`
+	// more keys to fall back on when the others are rate limited
+	APIKeys []string `json:"apiKeys,omitempty"`
+	// the most requests to make to the DataPoint API a minute, 0 for
+	// the free tier's limit
+	RequestsPerMinute int `json:"requestsPerMinute,omitempty"`
+	// minutes between refreshes of the forecast being viewed, 0 to not
+	// refresh, set from the settings screen
+	RefreshMinutes int `json:"refreshMinutes,omitempty"`
+	// the most matches a search lists, 0 for the default
+	SearchResults int `json:"searchResults,omitempty"`
+	// how loose a match a search lists, a distance growing with the
+	// letters of a name that aren't in the search, 0 for the default
+	SearchDistance int `json:"searchDistance,omitempty"`
+	// saved on quitting, for -resume
+	Session *Session `json:"session,omitempty"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		Resolution:      string(dailyResolution),
+		TemperatureUnit: "C",
+		WindUnit:        string(mphUnit),
+		Theme:           themes[0].Name,
+	}
+}
+
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find config directory: %w", err)
+	}
+
+	return filepath.Join(dir, "forecast", "config.json"), nil
+}
+
+// read the config file, writing one with the defaults if there isn't
+// one yet, settings missing from the file keep their defaults
+func loadConfig() (Config, error) {
+	cfg := defaultConfig()
+
+	path, err := configPath()
+	if err != nil {
+		return cfg, err
+	}
+
+	body, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, writeConfig(path, cfg)
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("could not read config: %w", err)
+	}
+
+	err = json.Unmarshal(body, &cfg)
+	if err != nil {
+		return defaultConfig(), fmt.Errorf("error decoding config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+func writeConfig(path string, cfg Config) error {
+	body, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding config: %w", err)
+	}
+
+	// the file can hold an API key, so only its owner may read it.
+	// WriteFile only sets the mode of a new file, one written by an
+	// older version is tightened too
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err == nil {
+		err = os.WriteFile(path, append(body, '\n'), 0o600)
+	}
+	if err == nil {
+		err = os.Chmod(path, 0o600)
+	}
+	if err != nil {
+		return fmt.Errorf("could not write config: %w", err)
+	}
+
+	return nil
+}
+
+// change a setting in the config file, a file that can't be read is
+// left alone rather than replaced with the defaults
+func updateConfig(change func(*Config)) error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	change(&cfg)
+
+	return writeConfig(path, cfg)
+}
+
+// remember a site as home, to be opened on launch
+func saveHome(locationId string) error {
+	return updateConfig(func(cfg *Config) { cfg.Location = locationId })
+}
+
+func saveApiKey(key string) error {
+	return updateConfig(func(cfg *Config) { cfg.APIKey = key })
+}
+
+// the settings of base with those given as flags replaced by overrides,
+// given holds the names of the flags set so that a zero value like
+// -days 0 still overrides the file
+func mergeConfig(base Config, overrides Config, given map[string]bool) Config {
+	if given["resolution"] {
+		base.Resolution = overrides.Resolution
+	}
+	if given["theme"] {
+		base.Theme = overrides.Theme
+	}
+	if given["location"] {
+		base.Location = overrides.Location
+	}
+	if given["days"] {
+		base.Days = overrides.Days
+	}
+	if given["lang"] {
+		base.Language = overrides.Language
+	}
+	if given["search-results"] {
+		base.SearchResults = overrides.SearchResults
+	}
+	if given["search-distance"] {
+		base.SearchDistance = overrides.SearchDistance
+	}
+
+	return base
+}
+
+// the names of the flags set on the command line
+func givenFlags() map[string]bool {
+	given := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { given[f.Name] = true })
+
+	return given
+}
+
+func languageNames() string {
+	var names []string
+	for _, lang := range data.Languages() {
+		names = append(names, string(lang))
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// set up a model from the config, rejecting values it doesn't know
+func applyConfig(m model, cfg Config) (model, error) {
+	switch resolution(cfg.Resolution) {
+	case dailyResolution, threeHourlyResolution:
+		m.forecastResolution = resolution(cfg.Resolution)
+	default:
+		return m, fmt.Errorf("unknown resolution %q, choose daily or 3hourly", cfg.Resolution)
+	}
+
+	switch strings.ToUpper(strings.TrimPrefix(cfg.TemperatureUnit, "°")) {
+	case "C":
+		m.tempUnit = celsiusUnit
+	case "F":
+		m.tempUnit = fahrenheitUnit
+	default:
+		return m, fmt.Errorf("unknown temperature unit %q, choose C or F", cfg.TemperatureUnit)
+	}
+
+	switch windUnit(strings.ToLower(cfg.WindUnit)) {
+	case mphUnit:
+		m.windUnit = mphUnit
+	case kphUnit:
+		m.windUnit = kphUnit
+	case knotsUnit:
+		m.windUnit = knotsUnit
+	default:
+		return m, fmt.Errorf("unknown wind unit %q, choose mph, km/h or kt", cfg.WindUnit)
+	}
+
+	themeIndex, ok := findTheme(cfg.Theme)
+	if !ok {
+		return m, fmt.Errorf("unknown theme %q, choose one of: %s", cfg.Theme, themeNames())
+	}
+	m.themeIndex = themeIndex
+
+	if cfg.RequestsPerMinute < 0 {
+		return m, fmt.Errorf("requestsPerMinute can't be negative, use 0 for the default")
+	}
+
+	if cfg.RefreshMinutes < 0 {
+		return m, fmt.Errorf("refreshMinutes can't be negative, use 0 to not refresh")
+	}
+	m.refreshInterval = time.Duration(cfg.RefreshMinutes) * time.Minute
+
+	if cfg.SearchResults < 0 || cfg.SearchDistance < 0 {
+		return m, fmt.Errorf("searchResults and searchDistance can't be negative, use 0 for the defaults")
+	}
+	m.searchResults, m.searchDistance = defaultSearchResults, defaultSearchDistance
+	if cfg.SearchResults > 0 {
+		m.searchResults = cfg.SearchResults
+	}
+	if cfg.SearchDistance > 0 {
+		m.searchDistance = cfg.SearchDistance
+	}
+
+	m.language = data.English
+	if cfg.Language != "" {
+		lang, ok := data.ParseLanguage(cfg.Language)
+		if !ok {
+			return m, fmt.Errorf("unknown language %q, choose one of: %s", cfg.Language, languageNames())
+		}
+		m.language = lang
+	}
+
+	m.favourites = cfg.Favourites
+	m.maxDays = max(0, cfg.Days)
+	m.twelveHour = cfg.TwelveHour
+
+	return m, nil
+}
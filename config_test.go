@@ -0,0 +1,140 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfigMissingFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(cfg, defaultConfig()) {
+		t.Errorf("expected the defaults, got %+v", cfg)
+	}
+
+	path, _ := configPath()
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected a default config to be written: %v", err)
+	}
+
+	// the written defaults read back the same
+	if again, err := loadConfig(); err != nil || !reflect.DeepEqual(again, cfg) {
+		t.Errorf("expected to read back %+v, got %+v and %v", cfg, again, err)
+	}
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	path, _ := configPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"resolution": "3hourly", "windUnit": "kt", "days": 3}`
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the file overrides the defaults it sets, flags override the file
+	// even when they're set to zero, but only those given
+	given := map[string]bool{"theme": true, "days": true}
+	cfg = mergeConfig(cfg, Config{Theme: "light", Days: 0, Location: "3772"}, given)
+
+	want := Config{
+		Resolution:      "3hourly",
+		TemperatureUnit: "C",
+		WindUnit:        "kt",
+		Theme:           "light",
+		Days:            0,
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+
+	m, err := applyConfig(model{}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.forecastResolution != threeHourlyResolution || m.windUnit != knotsUnit || m.tempUnit != celsiusUnit || m.maxDays != 0 {
+		t.Errorf("config not applied to the model: %+v", m)
+	}
+
+	if themes[m.themeIndex].Name != "light" {
+		t.Errorf("expected the light theme, got %q", themes[m.themeIndex].Name)
+	}
+}
+
+func TestSaveHome(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	path, _ := configPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"theme": "light"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveHome("3772"); err != nil {
+		t.Fatal(err)
+	}
+
+	// other settings in the file are kept
+	cfg, err := loadConfig()
+	if err != nil || cfg.Location != "3772" || cfg.Theme != "light" {
+		t.Errorf("expected the home saved alongside the theme, got %+v and %v", cfg, err)
+	}
+
+	// the file can hold an API key, so an existing one is made private
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("expected the config to be readable only by its owner, got %v", info.Mode())
+	}
+
+	// a broken file isn't overwritten
+	if err := os.WriteFile(path, []byte(`{"theme": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveHome("3772"); err == nil {
+		t.Error("expected an error saving into an unreadable config")
+	}
+}
+
+func TestApplyConfigRejectsUnknownValues(t *testing.T) {
+	for _, change := range []func(*Config){
+		func(cfg *Config) { cfg.Resolution = "hourly" },
+		func(cfg *Config) { cfg.TemperatureUnit = "K" },
+		func(cfg *Config) { cfg.WindUnit = "m/s" },
+		func(cfg *Config) { cfg.Theme = "neon" },
+		func(cfg *Config) { cfg.RefreshMinutes = -5 },
+		func(cfg *Config) { cfg.SearchResults = -1 },
+	} {
+		cfg := defaultConfig()
+		change(&cfg)
+		if _, err := applyConfig(model{}, cfg); err == nil {
+			t.Errorf("expected %+v to be rejected", cfg)
+		}
+	}
+}
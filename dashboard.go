@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jasonleelunn/forecast/internal/data"
+	"github.com/mattn/go-runewidth"
+)
+
+// the width inside each card's border
+const cardWidth = 24
+
+// a favourite's forecast for the dashboard, errors are kept per card so
+// one failed fetch doesn't hide the rest
+type dashboardCard struct {
+	loaded   bool
+	forecast forecastData
+	slot     time.Time
+	// where the site is, for whether it's day or night there, located
+	// is false if that isn't known
+	site    coordinates
+	located bool
+	err     error
+}
+
+type cardMsg struct {
+	locationId string
+	siteData   data.SiteData
+	err        error
+}
+
+func fetchCard(m model, locationId string) tea.Cmd {
+	return func() tea.Msg {
+		siteData, err := m.getSiteData(context.Background(), locationId, threeHourlyResolution)
+		return cardMsg{locationId: locationId, siteData: siteData, err: err}
+	}
+}
+
+// the period and forecast indexes of the 3hourly slot under way at now,
+// or the next to come, and when it starts, upcoming is false if they've
+// all passed and the indexes are the last slot's, start is zero if there
+// are no slots at all
+func slotIndexAt(siteData data.SiteData, now time.Time) (periodIndex, forecastIndex int, start time.Time, upcoming bool) {
+	for p, period := range siteData.Site.Info.Location.Periods {
+		date, err := parsePeriodDate(period.Date)
+		if err != nil {
+			continue
+		}
+
+		for f, forecast := range period.Forecasts {
+			t, ok := localSlotTime(date, forecast.Time)
+			if !ok {
+				continue
+			}
+
+			periodIndex, forecastIndex, start = p, f, t
+			if t.Add(3 * time.Hour).After(now) {
+				return periodIndex, forecastIndex, start, true
+			}
+		}
+	}
+
+	return periodIndex, forecastIndex, start, false
+}
+
+// the 3hourly slot under way at now, or the next to come, the last one
+// if they've all passed
+func currentSlot(siteData data.SiteData, now time.Time) (data.Forecast, time.Time, bool) {
+	p, f, start, _ := slotIndexAt(siteData, now)
+	if start.IsZero() {
+		return data.Forecast{}, time.Time{}, false
+	}
+
+	return siteData.Site.Info.Location.Periods[p].Forecasts[f], start, true
+}
+
+func handleCard(m model, msg cardMsg) model {
+	card := dashboardCard{loaded: true, err: msg.err}
+
+	if msg.err == nil {
+		forecast, slot, ok := currentSlot(msg.siteData, clock())
+		if ok {
+			card.forecast = flattenForecast(threeHourlyResolution, msg.siteData.Site.MetaInfo, forecast)
+			card.slot = slot
+
+			// the sitelist has the site's coordinates too, should the
+			// forecast leave them out
+			location := msg.siteData.Site.Info.Location
+			card.site, card.located = parseCoordinates(location.Lat, location.Lon)
+			if !card.located {
+				card.site, card.located = m.siteCoords[msg.locationId]
+			}
+		} else {
+			card.err = errors.New("the forecast has no slots")
+		}
+	} else {
+		slog.Warn("fetching favourite", "location", msg.locationId, "err", msg.err)
+	}
+
+	// the map is shared with earlier copies of the model
+	m.cards = maps.Clone(m.cards)
+	if m.cards == nil {
+		m.cards = map[string]dashboardCard{}
+	}
+	m.cards[msg.locationId] = card
+
+	return m
+}
+
+// show a card for each favourite, fetching them all at once
+func openDashboard(m model) (model, tea.Cmd) {
+	m.dashboardChosen = true
+	m.dashboardCursor = min(m.dashboardCursor, max(0, len(m.favourites)-1))
+	m.cards = map[string]dashboardCard{}
+
+	var cmds []tea.Cmd
+	for _, id := range m.favourites {
+		cmds = append(cmds, fetchCard(m, id))
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// add the selected search result to the favourites, or take it off
+func toggleFavourite(m model) model {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return m
+	}
+
+	id := row[idColumn]
+	favourites := slices.DeleteFunc(slices.Clone(m.favourites), func(f string) bool { return f == id })
+	removed := len(favourites) < len(m.favourites)
+	if !removed {
+		favourites = append(favourites, id)
+	}
+
+	if err := updateConfig(func(cfg *Config) { cfg.Favourites = favourites }); err != nil {
+		m.notice = "Couldn't save your favourites: " + err.Error()
+		return m
+	}
+
+	m.favourites = favourites
+	if removed {
+		m.notice = "Removed " + row[nameColumn] + " from favourites"
+	} else {
+		m.notice = "Added " + row[nameColumn] + " to favourites, press D to see them all"
+	}
+
+	return m
+}
+
+// how many cards fit side by side
+func cardsPerRow(width int) int {
+	h, _ := listStyle.GetFrameSize()
+	return max(1, (width-h)/(cardWidth+frameWidth(borderStyle)))
+}
+
+func updateDashboard(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if key.String() == "esc" {
+		m.dashboardChosen = false
+		return m, nil
+	}
+
+	if len(m.favourites) == 0 {
+		return m, nil
+	}
+
+	perRow := cardsPerRow(m.width)
+	last := len(m.favourites) - 1
+
+	switch key.String() {
+	case "left", "h":
+		m.dashboardCursor = max(0, m.dashboardCursor-1)
+	case "right", "l":
+		m.dashboardCursor = min(last, m.dashboardCursor+1)
+	case "up", "k":
+		if m.dashboardCursor >= perRow {
+			m.dashboardCursor -= perRow
+		}
+	case "down", "j":
+		if m.dashboardCursor+perRow <= last {
+			m.dashboardCursor += perRow
+		}
+	case "enter":
+		m.dashboardChosen = false
+		m.textInput.Blur()
+		return chooseLocation(m, m.favourites[m.dashboardCursor])
+	}
+
+	return m, nil
+}
+
+func cardView(m model, locationId string, selected bool) string {
+	name := locationId
+	for _, row := range m.allRows {
+		if row[idColumn] == locationId {
+			name = row[nameColumn]
+			break
+		}
+	}
+
+	lines := []string{lipgloss.NewStyle().Bold(true).Render(runewidth.Truncate(name, cardWidth, "…"))}
+
+	card, ok := m.cards[locationId]
+	switch {
+	case !ok || !card.loaded:
+		lines = append(lines, lipgloss.NewStyle().Foreground(paletteColor(grey)).Render("Loading…"), "")
+	case card.err != nil:
+		lines = append(lines, lipgloss.NewStyle().Foreground(paletteColor(pink)).Render("⚠ Couldn't load forecast"), "")
+	default:
+		fd := card.forecast
+		code := fd.WeatherCode
+		if card.located {
+			code = weatherCodeAt(card.slot, card.site.lat, card.site.lon, code)
+		}
+		conditions := clockTime(card.slot, m.twelveHour) + " " + weatherIcon(code) + " " + describeCode(code, m.language)
+
+		rain := missingValue
+		if percent, err := fd.PrecipitationPct(); err == nil {
+			rain = compactRain(percent)
+		}
+
+		lines = append(lines,
+			runewidth.Truncate(conditions, cardWidth, "…"),
+			renderTemp(fd.Temperature, m.tempUnit)+" · "+rain,
+		)
+	}
+
+	style := borderStyle.Copy().Width(cardWidth)
+	if selected {
+		style = style.BorderForeground(paletteColor(pink))
+	}
+
+	return style.Render(strings.Join(lines, "\n"))
+}
+
+// the favourites' cards in as many columns as the terminal fits
+func dashboardView(m model) string {
+	title := listTitleStyle().Render("Favourites")
+
+	if len(m.favourites) == 0 {
+		return listStyle.Render(title + "\n\n" + wrapText(m, "No favourites yet, press F on a search result to add one") + "\n\nPress esc to go back or q to quit")
+	}
+
+	perRow := cardsPerRow(m.width)
+
+	var rows []string
+	for start := 0; start < len(m.favourites); start += perRow {
+		var cards []string
+		for i := start; i < min(start+perRow, len(m.favourites)); i++ {
+			cards = append(cards, cardView(m, m.favourites[i], i == m.dashboardCursor))
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cards...))
+	}
+
+	hint := lipgloss.NewStyle().
+		Foreground(paletteColor(grey)).
+		Render("arrows to move, enter to open, esc for the search, q to quit")
+
+	text := title + "\n\n" + lipgloss.JoinVertical(lipgloss.Left, rows...) + "\n" + hint
+	if m.notice != "" {
+		text += "\n" + footerView(m)
+	}
+
+	return listStyle.Render(text)
+}
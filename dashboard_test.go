@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+// serves the offline data except for one site's forecast
+type failingSite struct {
+	id string
+}
+
+func (s failingSite) Get(ctx context.Context, url string) ([]byte, error) {
+	if strings.Contains(url, "/json/"+s.id+"?") {
+		return nil, errors.New("connection reset")
+	}
+
+	return data.Offline{}.Get(ctx, url)
+}
+
+func TestDashboard(t *testing.T) {
+	defer func(c func() time.Time) { clock = c }(clock)
+	clock = func() time.Time { return time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC) }
+
+	cfg := defaultConfig()
+	cfg.Favourites = []string{"310002", "3772", "3"}
+
+	m := initialModel(cfg)
+	m.fetcher = failingSite{id: "3"}
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = next.(model)
+	next, cmd := m.Update(loadSitelist(m)())
+	m = runCmd(next.(model), cmd)
+
+	if !m.dashboardChosen {
+		t.Fatal("expected the dashboard on launch with favourites")
+	}
+
+	// two cards fit side by side in 80 columns
+	view := m.View()
+	if line := screenLines(view)[lineOf(view, "Leeds")]; !strings.Contains(line, "Heathrow") {
+		t.Errorf("expected the first two cards on one row, got:\n%s", view)
+	}
+	if !strings.Contains(view, "☂ 8%") || !strings.Contains(view, "12:00") {
+		t.Errorf("expected the current slot's conditions, got:\n%s", view)
+	}
+
+	// the failed card is marked without hiding the others
+	if strings.Count(view, "Couldn't load forecast") != 1 {
+		t.Errorf("expected one card to have failed, got:\n%s", view)
+	}
+
+	press := func(key tea.KeyMsg) {
+		next, cmd = m.Update(key)
+		m = next.(model)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	if m.dashboardCursor != 2 {
+		t.Errorf("expected down to move to the card below, got %d", m.dashboardCursor)
+	}
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	press(tea.KeyMsg{Type: tea.KeyUp})
+	press(tea.KeyMsg{Type: tea.KeyRight})
+	if m.dashboardCursor != 1 {
+		t.Errorf("expected to end up on the second card, got %d", m.dashboardCursor)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.dashboardChosen || !m.locationChosen || m.locationId != "3772" {
+		t.Error("expected enter to open the selected favourite")
+	}
+}
+
+func TestCardNightSlot(t *testing.T) {
+	defer func(c func() time.Time) { clock = c }(clock)
+	clock = func() time.Time { return time.Date(2024, 1, 10, 21, 0, 0, 0, time.UTC) }
+
+	// a sunny code for a slot well after sunset in Leeds
+	leeds := data.SiteData{}
+	leeds.Site.Info.Location = data.Location{
+		Lat: "53.8",
+		Lon: "-1.55",
+		Periods: []data.Period{{
+			Date:      "2024-01-10Z",
+			Forecasts: []data.Forecast{{Time: "1260", WeatherCode: "1"}},
+		}},
+	}
+
+	// the location view has a site open where it's still afternoon, which
+	// mustn't be mistaken for the card's
+	m := model{siteCoords: map[string]coordinates{"4": {53.8, -1.55}}}
+	m.siteData.Site.Info.Location = data.Location{Lat: "40.7", Lon: "-74.0"}
+	m = handleCard(m, cardMsg{locationId: "3", siteData: leeds})
+	if view := cardView(m, "3", false); !strings.Contains(view, "21:00") || !strings.Contains(view, "Clear night") {
+		t.Errorf("expected the night code at 21:00, got:\n%s", view)
+	}
+
+	// a forecast without coordinates uses the sitelist's
+	leeds.Site.Info.Location.Lat, leeds.Site.Info.Location.Lon = "", ""
+	m = handleCard(m, cardMsg{locationId: "4", siteData: leeds})
+	if view := cardView(m, "4", false); !strings.Contains(view, "Clear night") {
+		t.Errorf("expected the night code from the sitelist's coordinates, got:\n%s", view)
+	}
+}
+
+func TestToggleFavourite(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	m := startedModel(defaultConfig(), data.Offline{})
+	m = focusTable(m)
+	id := m.table.SelectedRow()[idColumn]
+
+	press := func() {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+		m = next.(model)
+	}
+
+	press()
+	if cfg, err := loadConfig(); err != nil || len(cfg.Favourites) != 1 || cfg.Favourites[0] != id {
+		t.Fatalf("expected %s to be saved as a favourite, got %q and %v", id, cfg.Favourites, err)
+	}
+
+	press()
+	if cfg, _ := loadConfig(); len(cfg.Favourites) != 0 || len(m.favourites) != 0 {
+		t.Errorf("expected pressing F again to remove it, got %q", cfg.Favourites)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// lines taken by the title, issued line and hint around the day table
+const dayChrome = 8
+
+func dayColumns() []table.Column {
+	return []table.Column{
+		{Title: "Time", Width: 6},
+		{Title: "Temp", Width: 6},
+		{Title: "Feels", Width: 6},
+		{Title: "Wind", Width: 10},
+		{Title: "Gust", Width: 8},
+		{Title: "Rain", Width: 6},
+		{Title: "UV", Width: 3},
+	}
+}
+
+// a row for each 3hourly slot of a period
+func dayRows(m model, periodIndex int) []table.Row {
+	period := m.siteData.Site.Info.Location.Periods[periodIndex]
+	date, dateErr := parsePeriodDate(period.Date)
+
+	var rows []table.Row
+	for _, forecast := range period.Forecasts {
+		fd := getForecastData(m, forecast)
+
+		slot := fd.Time
+		if dateErr == nil {
+			if t, ok := localSlotTime(date, fd.Time); ok {
+				slot = clockTime(t, m.twelveHour)
+			}
+		}
+
+		rain := missingValue
+		if percent, err := fd.PrecipitationPct(); err == nil {
+			rain = compactRain(percent)
+		}
+
+		uv := missingValue
+		if index, err := fd.UVIndex(); err == nil {
+			uv = strconv.Itoa(index)
+		}
+
+		rows = append(rows, table.Row{
+			slot,
+			formatTemp(fd.Temperature, m.tempUnit),
+			formatTemp(fd.FeelsLikeTemp, m.tempUnit),
+			fd.WindDirection + " " + formatWind(fd.WindSpeed, m.windUnit),
+			formatWind(fd.GustSpeed, m.windUnit),
+			rain,
+			uv,
+		})
+	}
+
+	return rows
+}
+
+// open a table of every slot on the selected item's day
+func openDay(m model) model {
+	if m.forecastResolution != threeHourlyResolution || m.observing {
+		m.notice = "Switch to 3hourly forecasts with r for an hourly breakdown"
+		return m
+	}
+
+	item, ok := m.list.SelectedItem().(forecastItem)
+	if !ok {
+		return m
+	}
+
+	periodIndex, forecastIndex := item.Position()
+
+	_, v := listStyle.GetFrameSize()
+	m.dayTable = table.New(
+		table.WithColumns(dayColumns()),
+		table.WithRows(dayRows(m, periodIndex)),
+		table.WithHeight(max(1, m.height-v-dayChrome)),
+		table.WithFocused(true),
+		table.WithStyles(tableStyleFocussed),
+	)
+	m.dayTable.SetCursor(forecastIndex)
+	m.dayPeriod = periodIndex
+	m.dayChosen = true
+
+	return m
+}
+
+// select the list item for the slot under the day table's cursor
+func selectDaySlot(m model) model {
+	for i, item := range m.list.Items() {
+		periodIndex, forecastIndex := item.(forecastItem).Position()
+		if periodIndex == m.dayPeriod && forecastIndex == m.dayTable.Cursor() {
+			m.list.Select(i)
+			break
+		}
+	}
+
+	return m
+}
+
+func updateDay(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "enter":
+			m = selectDaySlot(m)
+			return openForecast(m)
+		case "esc":
+			// leave the list on the slot that was being looked at
+			m = selectDaySlot(m)
+			m.dayChosen = false
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.dayTable, cmd = m.dayTable.Update(msg)
+
+	return m, cmd
+}
+
+func dayView(m model) string {
+	title := m.siteData.Site.Info.Location.Name
+	if date, err := parsePeriodDate(m.siteData.Site.Info.Location.Periods[m.dayPeriod].Date); err == nil {
+		title += " - " + date.Format("Mon, 02 Jan 2006")
+	}
+
+	hint := lipgloss.NewStyle().
+		Foreground(paletteColor(grey)).
+		Render("enter for details, esc to go back, q to quit")
+
+	return listStyle.Render(title + "\n" + issuedView(m) + "\n\n" + borderStyle.Render(m.dayTable.View()) + "\n" + hint)
+}
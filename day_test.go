@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestDayTable(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Resolution = string(threeHourlyResolution)
+	m := startedModel(cfg, data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = next.(model)
+
+	m = runCmd(chooseLocation(m, "310002"))
+
+	// the second day has all eight slots
+	first := len(m.siteData.Site.Info.Location.Periods[0].Forecasts)
+	m.list.Select(first + 2)
+
+	press := func(key tea.KeyMsg) {
+		next, _ := m.Update(key)
+		m = next.(model)
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	if !m.dayChosen || m.dayPeriod != 1 {
+		t.Fatalf("expected the second day's table, got period %d", m.dayPeriod)
+	}
+
+	if rows := m.dayTable.Rows(); len(rows) != 8 || len(rows[0]) != len(dayColumns()) {
+		t.Fatalf("expected 8 full rows, got %v", rows)
+	}
+
+	if m.dayTable.Cursor() != 2 {
+		t.Errorf("expected the table to start on the selected slot, got %d", m.dayTable.Cursor())
+	}
+
+	view := dayView(m)
+	for _, want := range []string{"Thu, 11 Jan 2024", "Feels", "Gust", "06:00"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the day view:\n%s", want, view)
+		}
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyDown})
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.forecastChosen || m.list.Index() != first+3 {
+		t.Fatalf("expected the fourth slot's details, got list index %d", m.list.Index())
+	}
+
+	// back out to the table, then to the list
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.dayChosen || m.forecastChosen || !m.locationChosen {
+		t.Error("esc should go back through the day table to the list")
+	}
+}
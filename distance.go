@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+const earthRadiusMiles = 3958.8
+
+type coordinates struct {
+	lat, lon float64
+}
+
+// great-circle distance between two points in miles
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusMiles * math.Asin(math.Sqrt(a))
+}
+
+func parseCoordinates(lat, lon string) (coordinates, bool) {
+	latitude, errLat := strconv.ParseFloat(lat, 64)
+	longitude, errLon := strconv.ParseFloat(lon, 64)
+
+	return coordinates{latitude, longitude}, errLat == nil && errLon == nil
+}
+
+// order rows nearest first, adding the distance to each name,
+// sites without known coordinates are left out
+func rowsByDistance(rows Rows, coords map[string]coordinates, lat, lon float64) Rows {
+	var nearby Rows
+	distances := make(map[string]float64)
+
+	for _, row := range rows {
+		site, ok := coords[row[idColumn]]
+		if !ok {
+			continue
+		}
+
+		distance := haversine(lat, lon, site.lat, site.lon)
+		distances[row[idColumn]] = distance
+
+		row = slices.Clone(row)
+		row[nameColumn] = fmt.Sprintf("%s (%.1f mi)", row[nameColumn], distance)
+		nearby = append(nearby, row)
+	}
+
+	sort.SliceStable(nearby, func(i, j int) bool {
+		return distances[nearby[i][idColumn]] < distances[nearby[j][idColumn]]
+	})
+
+	return nearby
+}
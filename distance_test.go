@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// serves the same body for every url
+type staticSource string
+
+func (s staticSource) Get(ctx context.Context, url string) ([]byte, error) {
+	return []byte(s), nil
+}
+
+func TestHaversine(t *testing.T) {
+	// London to Edinburgh is roughly 332 miles as the crow flies
+	distance := haversine(51.5074, -0.1278, 55.9533, -3.1883)
+	if math.Abs(distance-332) > 2 {
+		t.Errorf("expected about 332 miles, got %.1f", distance)
+	}
+
+	if haversine(50, -1, 50, -1) != 0 {
+		t.Error("expected no distance between identical points")
+	}
+}
+
+func TestRowsByDistance(t *testing.T) {
+	rows := Rows{
+		{"Far", "1", "se"},
+		{"Near", "2", "se"},
+		{"Unknown", "3", "se"},
+	}
+	coords := map[string]coordinates{
+		"1": {lat: 55, lon: -3},
+		"2": {lat: 51.6, lon: -0.1},
+	}
+
+	sorted := rowsByDistance(rows, coords, 51.5, -0.1)
+
+	if len(sorted) != 2 || sorted[0][idColumn] != "2" || sorted[1][idColumn] != "1" {
+		t.Errorf("unexpected ordering %v", sorted)
+	}
+
+	if rows[1][nameColumn] != "Near" {
+		t.Error("original rows should be left unchanged")
+	}
+}
+
+func TestGeolocateIP(t *testing.T) {
+	m := model{fetcher: staticSource(`{"city": "Leeds", "latitude": 53.8, "longitude": -1.55}`)}
+	lat, lon, err := m.geolocateIP()
+	if err != nil || lat != 53.8 || lon != -1.55 {
+		t.Errorf("expected 53.8, -1.55, got %v, %v, %v", lat, lon, err)
+	}
+
+	m.fetcher = staticSource(`{"error": true, "reason": "Reserved IP Address"}`)
+	if _, _, err := m.geolocateIP(); err == nil {
+		t.Error("expected an error for an unknown address")
+	}
+
+	m.fetcher = failingSource{}
+	if _, _, err := m.geolocateIP(); err == nil {
+		t.Error("expected an error when the service can't be reached")
+	}
+}
+
+func TestShowNearest(t *testing.T) {
+	m := model{
+		allRows: Rows{{"Far", "1", "se"}, {"Near", "2", "se"}},
+		siteCoords: map[string]coordinates{
+			"1": {lat: 55, lon: -3},
+			"2": {lat: 51.6, lon: -0.1},
+		},
+		textInput: setupTextInput(),
+	}
+	m.tableRows = m.allRows
+	m.table = setupTable(m.allRows, 0)
+
+	failed := showNearest(m, geolocationMsg{err: errors.New("offline")})
+	if failed.notice != "" || failed.tableRows[0][idColumn] != "1" {
+		t.Error("a failed lookup should leave the search as it was")
+	}
+
+	m.textInput.SetValue("Fa")
+	if typed := showNearest(m, geolocationMsg{lat: 51.5, lon: -0.1}); typed.tableRows[0][idColumn] != "1" {
+		t.Error("a search already typed shouldn't be replaced")
+	}
+
+	m.textInput.SetValue("")
+	m = showNearest(m, geolocationMsg{lat: 51.5, lon: -0.1})
+	if m.tableRows[0][idColumn] != "2" {
+		t.Errorf("expected the nearest site first, got %v", m.tableRows)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// a preset restricting a location's forecasts to the days near now
+type dateFilter int
+
+const (
+	noFilter dateFilter = iota
+	todayFilter
+	tomorrowFilter
+	weekendFilter
+)
+
+func (f dateFilter) String() string {
+	switch f {
+	case todayFilter:
+		return "today"
+	case tomorrowFilter:
+		return "tomorrow"
+	case weekendFilter:
+		return "this weekend"
+	default:
+		return ""
+	}
+}
+
+// the keys choosing each filter in the location list, 0 clears it
+var dateFilterKeys = map[string]dateFilter{
+	"0": noFilter,
+	"1": todayFilter,
+	"2": tomorrowFilter,
+	"3": weekendFilter,
+}
+
+func dateFilterHelp() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("1"), key.WithHelp("1", "today")),
+		key.NewBinding(key.WithKeys("2"), key.WithHelp("2", "tomorrow")),
+		key.NewBinding(key.WithKeys("3"), key.WithHelp("3", "weekend")),
+		key.NewBinding(key.WithKeys("0"), key.WithHelp("0", "all days")),
+	}
+}
+
+// whether a period's date passes the filter, now is in local time and
+// the period dates are calendar days
+func (f dateFilter) includes(date, now time.Time) bool {
+	day := func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	today, date := day(now), day(date)
+
+	switch f {
+	case todayFilter:
+		return date.Equal(today)
+	case tomorrowFilter:
+		return date.Equal(today.AddDate(0, 0, 1))
+	case weekendFilter:
+		// on a Sunday the weekend is what's left of it, otherwise it's
+		// the Saturday and Sunday coming up, today included
+		saturday := today.AddDate(0, 0, (int(time.Saturday)-int(today.Weekday())+7)%7)
+		if today.Weekday() == time.Sunday {
+			saturday = today.AddDate(0, 0, -1)
+		}
+
+		return !date.Before(today) && !date.Before(saturday) && !date.After(saturday.AddDate(0, 0, 1))
+	default:
+		return true
+	}
+}
+
+// show only the forecasts passing a filter, starting on the slot under
+// way if it's among them
+func setDateFilter(m model, filter dateFilter) (model, tea.Cmd) {
+	if filter == m.dateFilter {
+		return m, nil
+	}
+
+	m.dateFilter = filter
+	m.notice = ""
+	if filter != noFilter {
+		m.notice = "Showing " + filter.String() + ", press 0 for all days"
+	}
+
+	// relistForecasts leaves an empty list alone, and a filter can empty it
+	cmd := m.list.SetItems(getForecastListItems(m))
+	m.list.Select(nowIndex(m.list.Items()))
+
+	return m, cmd
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestDateFilterIncludes(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	// 10 January 2024 was a Wednesday
+	wednesday := time.Date(2024, 1, 10, 15, 0, 0, 0, time.Local)
+	saturday := time.Date(2024, 1, 13, 9, 0, 0, 0, time.Local)
+	sunday := time.Date(2024, 1, 14, 9, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		filter dateFilter
+		now    time.Time
+		want   []int
+	}{
+		{noFilter, wednesday, []int{9, 10, 11, 12, 13, 14, 15}},
+		{todayFilter, wednesday, []int{10}},
+		{tomorrowFilter, wednesday, []int{11}},
+		{weekendFilter, wednesday, []int{13, 14}},
+		{weekendFilter, saturday, []int{13, 14}},
+		// on a Sunday only what's left of the weekend
+		{weekendFilter, sunday, []int{14}},
+	}
+
+	for _, test := range tests {
+		var got []int
+		for d := 9; d <= 15; d++ {
+			if test.filter.includes(day(d), test.now) {
+				got = append(got, d)
+			}
+		}
+
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%q on %s: got days %v, want %v", test.filter, test.now.Weekday(), got, test.want)
+		}
+	}
+}
+
+func TestDateFilterKeys(t *testing.T) {
+	defer func(c func() time.Time) { clock = c }(clock)
+	clock = func() time.Time { return time.Date(2024, 1, 10, 12, 0, 0, 0, time.Local) }
+
+	m := startedModel(defaultConfig(), data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = runCmd(chooseLocation(next.(model), "310002"))
+	all := len(m.list.Items())
+
+	press := func(key string) {
+		next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = runCmd(next.(model), cmd)
+	}
+
+	press("3")
+	items := m.list.Items()
+	if len(items) != 4 || !strings.HasPrefix(items[0].(forecastItem).Title(), "Sat, 13 Jan") {
+		t.Fatalf("expected the weekend's day and night forecasts, got %d items", len(items))
+	}
+	if !strings.Contains(statusBar(m), "this weekend") {
+		t.Errorf("expected the filter in the status bar, got %q", statusBar(m))
+	}
+
+	// the filter sticks when the resolution changes
+	press("r")
+	if m.forecastResolution != threeHourlyResolution || len(m.list.Items()) == 0 {
+		t.Fatalf("expected 3hourly weekend forecasts, got %d items", len(m.list.Items()))
+	}
+	for _, item := range m.list.Items() {
+		if title := item.(forecastItem).Title(); !strings.HasPrefix(title, "Sat") && !strings.HasPrefix(title, "Sun") {
+			t.Errorf("expected only weekend slots after switching resolution, got %q", title)
+		}
+	}
+
+	press("r")
+	press("0")
+	if len(m.list.Items()) != all || strings.Contains(statusBar(m), "weekend") {
+		t.Errorf("expected 0 to show every day again, got %d of %d", len(m.list.Items()), all)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// only asked when -nearest is given, as it sends the user's IP address
+// to a third party
+const geolocationUrl = "https://ipapi.co/json/"
+
+type geolocationMsg struct {
+	lat, lon float64
+	err      error
+}
+
+// look up the approximate latitude and longitude of the user's public IP
+func (m model) geolocateIP() (lat, lon float64, err error) {
+	body, err := m.fetcher.Get(context.Background(), geolocationUrl)
+	if err != nil {
+		return 0, 0, fmt.Errorf("location lookup unavailable: %w", err)
+	}
+
+	var res struct {
+		Error     bool     `json:"error"`
+		Reason    string   `json:"reason"`
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+	}
+
+	err = json.Unmarshal(body, &res)
+	if err != nil {
+		return 0, 0, fmt.Errorf("location lookup unavailable: %w", err)
+	}
+
+	if res.Error || res.Latitude == nil || res.Longitude == nil {
+		return 0, 0, fmt.Errorf("location not found: %s", res.Reason)
+	}
+
+	return *res.Latitude, *res.Longitude, nil
+}
+
+func locateByIP(m model) tea.Cmd {
+	return func() tea.Msg {
+		lat, lon, err := m.geolocateIP()
+		return geolocationMsg{lat: lat, lon: lon, err: err}
+	}
+}
+
+// list the sites nearest the user, unless the lookup failed or they've
+// started searching in the meantime
+func showNearest(m model, msg geolocationMsg) model {
+	if msg.err != nil {
+		slog.Debug("geolocation failed", "err", msg.err)
+		return m
+	}
+
+	if m.textInput.Value() != "" {
+		return m
+	}
+
+	m.notice = "Sites nearest your approximate location"
+	m = showRows(m, rowsByDistance(m.allRows, m.siteCoords, msg.lat, msg.lon))
+	m.table.GotoTop()
+
+	return m
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// the rune positions in name of each character of query, matched in
+// order ignoring case as the fuzzy search does, or nil when they don't
+// all match
+func matchedPositions(query string, name string) []int {
+	target := []rune(name)
+
+	var positions []int
+	next := 0
+	for _, q := range query {
+		if unicode.IsSpace(q) {
+			continue
+		}
+
+		found := false
+		for ; next < len(target); next++ {
+			if equalFold(q, target[next]) {
+				positions = append(positions, next)
+				next++
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil
+		}
+	}
+
+	return positions
+}
+
+func equalFold(a, b rune) bool {
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
+
+// restyle a plain table row line, picking out the matched characters of
+// its name cell, which starts after the cell's padding
+func highlightRow(line string, name string, width int, positions []int, base lipgloss.Style, match lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(line)
+	}
+
+	// characters cut off by truncation can't be highlighted
+	shown := len([]rune(runewidth.Truncate(name, width, "…")))
+	if shown < len([]rune(name)) {
+		shown--
+	}
+
+	highlighted := make(map[int]bool)
+	for _, p := range positions {
+		if p < shown {
+			highlighted[p+1] = true
+		}
+	}
+
+	var out, segment string
+	inMatch := false
+	flush := func() {
+		if segment == "" {
+			return
+		}
+
+		if inMatch {
+			out += match.Render(segment)
+		} else {
+			out += base.Render(segment)
+		}
+		segment = ""
+	}
+
+	for i, r := range []rune(line) {
+		if highlighted[i] != inMatch {
+			flush()
+			inMatch = highlighted[i]
+		}
+		segment += string(r)
+	}
+	flush()
+
+	return out
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestMatchedPositions(t *testing.T) {
+	tests := []struct {
+		query, name string
+		want        []int
+	}{
+		{"leds", "Leeds", []int{0, 1, 3, 4}},
+		{"HTH", "Heathrow", []int{0, 3, 4}},
+		{"new port", "Newport", []int{0, 1, 2, 3, 4, 5, 6}},
+		{"xyz", "Leeds", nil},
+		{"", "Leeds", nil},
+	}
+
+	for _, test := range tests {
+		if got := matchedPositions(test.query, test.name); !slices.Equal(got, test.want) {
+			t.Errorf("%q in %q: got %v, want %v", test.query, test.name, got, test.want)
+		}
+	}
+}
+
+func TestHighlightSearchMatches(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	rows := Rows{{"Heathrow", "3772", "se"}, {"Leeds", "310002", "yh"}}
+	m := model{table: setupTable(rows, 80), tableRows: rows, allRows: rows, placenames: []string{"Heathrow", "Leeds"}, textInput: setupTextInput(), width: 80}
+
+	m.textInput.SetValue("lds")
+	m = filterTable(m)
+
+	view := stripeTable(m)
+	if !strings.Contains(view, "\x1b[1;") {
+		t.Errorf("expected matched characters to be bold, got %q", view)
+	}
+
+	// the styling doesn't change what's drawn or how wide it is
+	if got, want := screenLines(view), screenLines(m.table.View()); !slices.Equal(got, want) {
+		t.Errorf("highlighting changed the table, got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// clearing the search clears the highlighting
+	m.textInput.SetValue("")
+	m = filterTable(m)
+	if view := stripeTable(m); strings.Contains(view, "\x1b[1;") {
+		t.Errorf("expected no highlighting without a search, got %q", view)
+	}
+}
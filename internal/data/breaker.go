@@ -0,0 +1,101 @@
+package data
+
+import (
+	"sync"
+	"time"
+)
+
+// trip after this many failures in a row within the window, then turn
+// requests away for the cooldown
+const (
+	DefaultBreakerFailures = 5
+	DefaultBreakerWindow   = time.Minute
+	DefaultBreakerCooldown = 30 * time.Second
+)
+
+// BreakerOpenError is returned without making a request while the
+// breaker is open after repeated failures
+type BreakerOpenError struct {
+	// when a request will be tried again
+	Until time.Time
+}
+
+func (e *BreakerOpenError) Error() string {
+	return "the service appears to be down, not retrying until " + e.Until.Format("15:04:05")
+}
+
+// Breaker stops requests to a service that keeps failing, so an outage
+// fails fast rather than waiting out every timeout and retry. Once the
+// cooldown is over requests are tried again, a success closes the
+// breaker and a failure reopens it straight away
+type Breaker struct {
+	failures int
+	window   time.Duration
+	cooldown time.Duration
+
+	mu sync.Mutex
+	// the failures since the last success, oldest first
+	recent    []time.Time
+	openUntil time.Time
+
+	now func() time.Time
+}
+
+func NewBreaker(failures int, window, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		failures: max(1, failures),
+		window:   window,
+		cooldown: cooldown,
+		now:      time.Now,
+	}
+}
+
+// Allow returns a *BreakerOpenError if requests are being turned away
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.now().Before(b.openUntil) {
+		return &BreakerOpenError{Until: b.openUntil}
+	}
+
+	return nil
+}
+
+// Open reports whether requests are being turned away, and until when
+func (b *Breaker) Open() (time.Time, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.openUntil, b.now().Before(b.openUntil)
+}
+
+// Succeeded closes the breaker
+func (b *Breaker) Succeeded() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.recent = nil
+	b.openUntil = time.Time{}
+}
+
+// Failed counts a failure, opening the breaker once there are enough
+func (b *Breaker) Failed() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+
+	// the request let through after a cooldown failing reopens it
+	halfOpen := !b.openUntil.IsZero()
+
+	// failures spread out over longer than the window aren't an outage
+	for len(b.recent) > 0 && now.Sub(b.recent[0]) > b.window {
+		b.recent = b.recent[1:]
+	}
+	b.recent = append(b.recent, now)
+
+	if halfOpen || len(b.recent) >= b.failures {
+		b.openUntil = now.Add(b.cooldown)
+	}
+}
@@ -0,0 +1,333 @@
+package data
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Client fetches endpoints, retrying requests which fail to get a response
+type Client struct {
+	HTTPClient *http.Client
+	// how long a request may go without hearing from the server, reset
+	// as the body arrives so large downloads aren't cut off. 0 to wait
+	// as long as it takes
+	Timeout time.Duration
+	// how many more times to try after the first attempt fails
+	Retries int
+	// wait before the first retry, doubled for each one after
+	Backoff time.Duration
+	// spaces out requests for the DataPoint API, nil for no limit
+	Limiter *Limiter
+	// fails requests fast during an outage, nil to always try
+	Breaker *Breaker
+	// counts requests and their timings, nil to not bother
+	Metrics *Metrics
+	// set with SetKeys to spread requests across several API keys
+	keys *keyPool
+}
+
+// StatusError is returned when a response has a status other than 200 OK
+type StatusError struct {
+	Code int
+	// from the Retry-After header, if there was one
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected HTTP status %d", e.Code)
+}
+
+// the first bytes of any gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+var errIdle = errors.New("timed out waiting for the server")
+
+var DefaultClient = NewClient()
+
+func NewClient() *Client {
+	return &Client{
+		HTTPClient: &http.Client{},
+		Timeout:    10 * time.Second,
+		Retries:    2,
+		Backoff:    500 * time.Millisecond,
+		Limiter:    NewLimiter(DefaultRequestsPerMinute),
+		Breaker:    NewBreaker(DefaultBreakerFailures, DefaultBreakerWindow, DefaultBreakerCooldown),
+	}
+}
+
+// AddCACert trusts the PEM certificates in the file at path as well as
+// the system's, for proxies that re-sign traffic with their own CA.
+// Proxies set with HTTP_PROXY and HTTPS_PROXY are used either way
+func (c *Client) AddCACert(path string) error {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read CA certificate: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no certificates found in %s", path)
+	}
+
+	// keep the default transport's proxy settings and timeouts
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	c.HTTPClient.Transport = transport
+
+	return nil
+}
+
+// SetKeys has requests for the DataPoint API use the first of keys that
+// isn't rate limited, moving on to the next when one gets a 429 response
+func (c *Client) SetKeys(keys []string) {
+	c.keys = newKeyPool(keys)
+}
+
+// Get requests url, giving up early if ctx is cancelled, client errors
+// like a rejected key are returned straight away as retrying won't help
+func (c *Client) Get(ctx context.Context, url string) ([]byte, error) {
+	// like the limiter the breaker only covers the DataPoint API, an
+	// outage elsewhere shouldn't stop forecasts being fetched
+	breaker := c.Breaker != nil && hasKey(url)
+	if breaker {
+		if err := c.Breaker.Allow(); err != nil {
+			c.Metrics.rejected()
+			return nil, err
+		}
+	}
+
+	body, err := c.getWithRetries(ctx, url)
+
+	// a request that took a few tries is one outage, not several
+	if breaker {
+		c.recordOutcome(ctx, err)
+	}
+
+	return body, err
+}
+
+func (c *Client) getWithRetries(ctx context.Context, url string) ([]byte, error) {
+	var err error
+	backoff := c.Backoff
+
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+				backoff *= 2
+			}
+
+			c.Metrics.retried()
+		}
+
+		slog.Debug("fetching", "url", Redact(url), "attempt", attempt+1)
+
+		var body []byte
+		body, err = c.getWithKeys(ctx, url)
+		if err == nil || ctx.Err() != nil {
+			return body, err
+		}
+
+		// waiting a few seconds won't bring back a rate limited key
+		var exhaustedErr *KeysExhaustedError
+		if errors.As(err, &exhaustedErr) {
+			return nil, err
+		}
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.Code < http.StatusInternalServerError {
+			return nil, err
+		}
+
+		slog.Warn("fetch failed", "url", Redact(url), "attempt", attempt+1, "err", Redact(err.Error()))
+	}
+
+	return nil, err
+}
+
+// tell the breaker how a request went, only failures of the service
+// itself count towards opening it
+func (c *Client) recordOutcome(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	var exhaustedErr *KeysExhaustedError
+	var statusErr *StatusError
+	switch {
+	case err == nil:
+		c.Breaker.Succeeded()
+	case errors.As(err, &exhaustedErr):
+	case errors.As(err, &statusErr) && statusErr.Code < http.StatusInternalServerError:
+		// it answered, just not with what we asked for
+		c.Breaker.Succeeded()
+	default:
+		c.Breaker.Failed()
+	}
+}
+
+// get url with each key from the pool in turn until one isn't turned
+// away for making too many requests
+func (c *Client) getWithKeys(ctx context.Context, url string) ([]byte, error) {
+	if c.keys == nil {
+		return c.get(ctx, url)
+	}
+
+	for {
+		key, err := c.keys.next()
+		if err != nil {
+			return nil, err
+		}
+
+		keyedUrl, err := withKey(url, key)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := c.get(ctx, keyedUrl)
+
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.Code != http.StatusTooManyRequests {
+			return body, err
+		}
+
+		slog.Warn("API key rate limited", "url", Redact(keyedUrl), "retryAfter", statusErr.RetryAfter)
+		c.Metrics.rateLimited()
+		c.keys.limit(key, statusErr.RetryAfter)
+	}
+}
+
+func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
+	// only the DataPoint API's requests, which have a key, are limited
+	if c.Limiter != nil && hasKey(url) {
+		if err := c.Limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// only time requests if someone's counting
+	if c.Metrics == nil {
+		return c.request(ctx, url)
+	}
+
+	start := time.Now()
+	body, err := c.request(ctx, url)
+	c.Metrics.fetched(time.Since(start), err)
+
+	return body, err
+}
+
+func (c *Client) request(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// the deadline moves on whenever some of the body arrives, only a
+	// server that's gone quiet is given up on
+	idle := &idleReader{}
+	if c.Timeout > 0 {
+		idle.timer = time.AfterFunc(c.Timeout, func() { cancel(errIdle) })
+		idle.timeout = c.Timeout
+		defer idle.timer.Stop()
+	}
+
+	body, err := c.requestBody(ctx, url, idle)
+	if err != nil && errors.Is(context.Cause(ctx), errIdle) {
+		return nil, fmt.Errorf("error fetching endpoint: %w", errIdle)
+	}
+
+	return body, err
+}
+
+func (c *Client) requestBody(ctx context.Context, url string, idle *idleReader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	// asking for gzip ourselves means decompressing it ourselves too
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	res, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching endpoint: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	// error pages aren't JSON so don't bother reading them
+	if res.StatusCode != http.StatusOK {
+		return nil, &StatusError{
+			Code:       res.StatusCode,
+			RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	// the length is of the body as sent, so progress counts it before
+	// it's decompressed
+	idle.reader = res.Body
+	var reader io.Reader = idle
+	if progress := ProgressFrom(ctx); progress != nil {
+		progress(0, res.ContentLength)
+		reader = &progressReader{reader: idle, total: res.ContentLength, progress: progress}
+	}
+
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %w", err)
+	}
+
+	return decompress(body, res.Header.Get("Content-Encoding"))
+}
+
+// restarts timer each time something is read through it
+type idleReader struct {
+	reader  io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 && r.timer != nil {
+		r.timer.Reset(r.timeout)
+	}
+
+	return n, err
+}
+
+// gunzip a body sent with gzip encoding, including by proxies which
+// compress it without saying so
+func decompress(body []byte, encoding string) ([]byte, error) {
+	if !strings.EqualFold(encoding, "gzip") && !bytes.HasPrefix(body, gzipMagic) {
+		return body, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing body: %w", err)
+	}
+	defer reader.Close()
+
+	body, err = io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing body: %w", err)
+	}
+
+	return body, nil
+}
@@ -1,47 +1,47 @@
 package data
 
 import (
-	"fmt"
-	"io"
-	"net/http"
-	"time"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
 )
 
 // see https://www.metoffice.gov.uk/binaries/content/assets/metofficegovuk/pdf/data/datapoint_api_reference.pdf
 // for full API schema details
 
-// some codes are duplicated for (day) and (night)
+// some conditions have separate (day) and (night) codes
 var WeatherCodes = map[string]string{
 	"0":  "Clear night",
 	"1":  "Sunny day",
-	"2":  "Partly cloudy",
-	"3":  "Partly cloudy",
+	"2":  "Partly cloudy (night)",
+	"3":  "Partly cloudy (day)",
 	"4":  "Not used",
 	"5":  "Mist",
 	"6":  "Fog",
 	"7":  "Cloudy",
 	"8":  "Overcast",
-	"9":  "Light rain shower",
-	"10": "Light rain shower",
+	"9":  "Light rain shower (night)",
+	"10": "Light rain shower (day)",
 	"11": "Drizzle",
 	"12": "Light rain",
-	"13": "Heavy rain shower",
-	"14": "Heavy rain shower",
+	"13": "Heavy rain shower (night)",
+	"14": "Heavy rain shower (day)",
 	"15": "Heavy rain",
-	"16": "Sleet shower",
-	"17": "Sleet shower",
+	"16": "Sleet shower (night)",
+	"17": "Sleet shower (day)",
 	"18": "Sleet",
-	"19": "Hail shower",
-	"20": "Hail shower",
+	"19": "Hail shower (night)",
+	"20": "Hail shower (day)",
 	"21": "Hail",
-	"22": "Light snow shower",
-	"23": "Light snow shower",
+	"22": "Light snow shower (night)",
+	"23": "Light snow shower (day)",
 	"24": "Light snow",
-	"25": "Heavy snow shower",
-	"26": "Heavy snow shower",
+	"25": "Heavy snow shower (night)",
+	"26": "Heavy snow shower (day)",
 	"27": "Heavy snow",
-	"28": "Thunder shower",
-	"29": "Thunder shower",
+	"28": "Thunder shower (night)",
+	"29": "Thunder shower (day)",
 	"30": "Thunder",
 }
 
@@ -51,11 +51,42 @@ type Forecast struct {
 	Visibility    string `json:"V"`
 	WindDirection string `json:"D"`
 	WindSpeed     string `json:"S"`
-	Day
-	Night
-	Hourly
+	// the API reuses keys like "U" across resolutions, so these are
+	// filled in by UnmarshalJSON depending on the kind of forecast
+	Day      Day      `json:"-"`
+	Night    Night    `json:"-"`
+	Hourly   Hourly   `json:"-"`
+	Observed Observed `json:"-"`
 }
 
+// daily forecasts are labelled "Day" or "Night", anything else
+// is a time of day from a 3hourly forecast or an observation
+func (f *Forecast) UnmarshalJSON(b []byte) error {
+	// a distinct type without this method, to avoid recursing
+	type forecast Forecast
+
+	err := json.Unmarshal(b, (*forecast)(f))
+	if err != nil {
+		return err
+	}
+
+	switch f.Time {
+	case "Day":
+		return json.Unmarshal(b, &f.Day)
+	case "Night":
+		return json.Unmarshal(b, &f.Night)
+	default:
+		err = json.Unmarshal(b, &f.Hourly)
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(b, &f.Observed)
+	}
+}
+
+// the Precipitation of each kind of forecast is the probability of
+// rain in percent, point forecasts don't give an amount
 type Day struct {
 	UV            string `json:"U"`
 	Precipitation string `json:"PPd"`
@@ -82,20 +113,44 @@ type Hourly struct {
 	FeelsLikeTemp string `json:"F"`
 }
 
+// observations share the Hourly fields for temperature ("T"), humidity ("H")
+// and gust speed ("G") but have no UV, precipitation or feels like values,
+// instead reporting pressure, its tendency and the dew point
+type Observed struct {
+	Pressure         string `json:"P"`
+	PressureTendency string `json:"Pt"`
+	DewPoint         string `json:"Dp"`
+}
+
 type Period struct {
-	Time      string     `json:"type"`
-	Date      string     `json:"value"`
-	Forecasts []Forecast `json:"Rep"`
+	Time      string    `json:"type"`
+	Date      string    `json:"value"`
+	Forecasts Forecasts `json:"Rep"`
+}
+
+// a period with a single forecast, e.g. the only observation so far
+// today, has it as an object rather than an array of one
+type Forecasts []Forecast
+
+func (f *Forecasts) UnmarshalJSON(b []byte) error {
+	return unmarshalOneOrMany(b, (*[]Forecast)(f))
 }
 
 type Location struct {
-	Id        string   `json:"i"`
-	Lat       string   `json:"lat"`
-	Lon       string   `json:"lon"`
-	Name      string   `json:"name"`
-	Country   string   `json:"country"`
-	Continent string   `json:"continent"`
-	Periods   []Period `json:"Period"`
+	Id        string  `json:"i"`
+	Lat       string  `json:"lat"`
+	Lon       string  `json:"lon"`
+	Name      string  `json:"name"`
+	Country   string  `json:"country"`
+	Continent string  `json:"continent"`
+	Periods   Periods `json:"Period"`
+}
+
+// as with Forecasts, a single period comes as an object
+type Periods []Period
+
+func (p *Periods) UnmarshalJSON(b []byte) error {
+	return unmarshalOneOrMany(b, (*[]Period)(p))
 }
 
 type Info struct {
@@ -123,25 +178,89 @@ type SiteData struct {
 	Site Site `json:"SiteRep"`
 }
 
-func Fetch(url string) []byte {
-	c := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+// Capabilities lists the timesteps the API has forecasts for at a
+// resolution, from the latest run issued at DataDate
+type Capabilities struct {
+	Resource struct {
+		DataDate   string `json:"dataDate"`
+		Resolution string `json:"res"`
+		TimeSteps  struct {
+			TS []string `json:"TS"`
+		} `json:"TimeSteps"`
+	} `json:"Resource"`
+}
 
-	res, err := c.Get(url)
+// the regional text forecasts are written by forecasters rather than
+// generated for a site
+type RegionalForecast struct {
+	Forecast struct {
+		IssuedAt string `json:"issuedAt"`
+		RegionId string `json:"regionId"`
+		Periods  struct {
+			Period []RegionalPeriod `json:"Period"`
+		} `json:"FcstPeriods"`
+	} `json:"RegionalFcst"`
+}
 
-	if err != nil {
-		fmt.Println("Error fetching endpoint:", err)
+type RegionalPeriod struct {
+	Id         string     `json:"id"`
+	Paragraphs Paragraphs `json:"Paragraph"`
+}
+
+type Paragraph struct {
+	Title string `json:"title"`
+	Text  string `json:"$"`
+}
+
+// periods with a single paragraph have it as an object rather than
+// an array of one
+type Paragraphs []Paragraph
+
+func (p *Paragraphs) UnmarshalJSON(b []byte) error {
+	return unmarshalOneOrMany(b, (*[]Paragraph)(p))
+}
+
+// the API drops the array around lists of one, so decode either an
+// object or an array of them into a slice
+func unmarshalOneOrMany[T any](b []byte, s *[]T) error {
+	if trimmed := bytes.TrimSpace(b); len(trimmed) > 0 && trimmed[0] == '{' {
+		var single T
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return err
+		}
+		*s = []T{single}
 		return nil
 	}
 
-	defer res.Body.Close()
+	return json.Unmarshal(b, s)
+}
 
-	body, err := io.ReadAll(res.Body)
+// severe weather warnings come as an RSS feed for each region
+type WarningsFeed struct {
+	Items []WarningItem `xml:"channel>item"`
+}
+
+type WarningItem struct {
+	Title       string `xml:"title"`
+	Description string `xml:"description"`
+	Link        string `xml:"link"`
+	PubDate     string `xml:"pubDate"`
+}
+
+// Fetch is FetchContext without cancellation, logging any error and
+// returning nil in its place
+func Fetch(url string) []byte {
+	body, err := FetchContext(context.Background(), url)
 	if err != nil {
-		fmt.Println("Error reading body:", err)
+		slog.Error("fetch failed", "url", Redact(url), "err", Redact(err.Error()))
 		return nil
 	}
 
 	return body
 }
+
+// FetchContext requests url from the DefaultSource, giving up early if
+// ctx is cancelled
+func FetchContext(ctx context.Context, url string) ([]byte, error) {
+	return DefaultSource.Get(ctx, url)
+}
@@ -2,10 +2,22 @@ package data
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestFetch(t *testing.T) {
@@ -23,3 +35,801 @@ func TestFetch(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestFetchStatusError(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "<html>Forbidden</html>", http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	client := NewClient()
+	client.Backoff = time.Millisecond
+
+	body, err := client.Get(context.Background(), ts.URL)
+
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusForbidden {
+		t.Fatalf("expected a 403 status error, got body %q and error %v", body, err)
+	}
+
+	if requests != 1 {
+		t.Errorf("a client error shouldn't be retried, got %d requests", requests)
+	}
+}
+
+func TestWeatherCodes(t *testing.T) {
+	for code := 0; code <= 30; code++ {
+		if WeatherCodes[strconv.Itoa(code)] == "" {
+			t.Errorf("missing description for weather code %d", code)
+		}
+	}
+
+	// night/day pairs of the same conditions
+	pairs := [][2]string{
+		{"0", "1"}, {"2", "3"}, {"9", "10"}, {"13", "14"}, {"16", "17"},
+		{"19", "20"}, {"22", "23"}, {"25", "26"}, {"28", "29"},
+	}
+
+	for _, pair := range pairs {
+		night, day := WeatherCodes[pair[0]], WeatherCodes[pair[1]]
+		if night == day {
+			t.Errorf("codes %s and %s share the description %q", pair[0], pair[1], night)
+		}
+	}
+}
+
+func TestFetchContextCancelled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	body, err := FetchContext(ctx, ts.URL)
+	if err == nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancellation error, got body %q and error %v", body, err)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	urls := []string{
+		"http://example.com/val/wxfcs/all/json/sitelist?key=",
+		"http://example.com/val/wxobs/all/json/sitelist?key=",
+		"http://example.com/val/wxfcs/all/json/310002?key=&res=daily",
+		"http://example.com/val/wxfcs/all/json/310002?key=&res=3hourly",
+		"http://example.com/val/wxobs/all/json/3772?key=&res=hourly",
+		"http://example.com/txt/wxfcs/regionalforecast/json/511?key=",
+		"https://example.com/public/data/PWSCache/WarningsRSS/Region/yh",
+	}
+
+	for _, url := range urls {
+		body, err := Offline{}.Get(context.Background(), url)
+		if err != nil || len(body) == 0 {
+			t.Errorf("expected offline data for %s, got error %v", url, err)
+		}
+	}
+
+	if _, err := (Offline{}).Get(context.Background(), "http://example.com/unknown"); err == nil {
+		t.Error("expected an error for an unknown endpoint")
+	}
+}
+
+func TestUnmarshalForecast(t *testing.T) {
+	var daily, hourly SiteData
+
+	for name, siteData := range map[string]*SiteData{
+		"fixtures/forecast_daily.json":   &daily,
+		"fixtures/forecast_3hourly.json": &hourly,
+	} {
+		body, err := fixtures.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = json.Unmarshal(body, siteData)
+		if err != nil {
+			t.Fatalf("error decoding %s: %v", name, err)
+		}
+	}
+
+	day := daily.Site.Info.Location.Periods[0].Forecasts[0]
+	if day.Time != "Day" || day.Day.UV == "" || day.Day.Temperature == "" {
+		t.Errorf("daily forecast missing day values: %+v", day)
+	}
+
+	night := daily.Site.Info.Location.Periods[0].Forecasts[1]
+	if night.Time != "Night" || night.Night.Temperature == "" || night.Day != (Day{}) {
+		t.Errorf("daily forecast has wrong night values: %+v", night)
+	}
+
+	slot := hourly.Site.Info.Location.Periods[0].Forecasts[0]
+	if slot.Hourly.UV == "" || slot.Hourly.Precipitation == "" || slot.Day != (Day{}) {
+		t.Errorf("3hourly forecast has wrong values: %+v", slot)
+	}
+}
+
+func TestUnmarshalSingleElements(t *testing.T) {
+	// a site with one period holding one observation, as happens just
+	// after midnight
+	single := `{"SiteRep": {"DV": {"Location": {"i": "3772", "Period":
+		{"type": "Day", "value": "2024-01-10Z", "Rep": {"$": "0", "T": "4", "P": "1012"}}}}}}`
+	array := `{"SiteRep": {"DV": {"Location": {"i": "3772", "Period":
+		[{"type": "Day", "value": "2024-01-10Z", "Rep": [{"$": "0", "T": "4", "P": "1012"}]}]}}}}`
+
+	var fromSingle, fromArray SiteData
+	if err := json.Unmarshal([]byte(single), &fromSingle); err != nil {
+		t.Fatalf("error decoding single elements: %v", err)
+	}
+	if err := json.Unmarshal([]byte(array), &fromArray); err != nil {
+		t.Fatal(err)
+	}
+
+	periods := fromSingle.Site.Info.Location.Periods
+	if len(periods) != 1 || len(periods[0].Forecasts) != 1 {
+		t.Fatalf("expected one period with one forecast, got %+v", periods)
+	}
+
+	observed := periods[0].Forecasts[0]
+	if observed.Hourly.Temperature != "4" || observed.Observed.Pressure != "1012" {
+		t.Errorf("single forecast decoded without its values: %+v", observed)
+	}
+
+	if !reflect.DeepEqual(fromSingle, fromArray) {
+		t.Errorf("expected both forms to decode the same, got %+v and %+v", fromSingle, fromArray)
+	}
+
+	var forecasts Forecasts
+	if err := json.Unmarshal([]byte(`"not a forecast"`), &forecasts); err == nil {
+		t.Error("expected an error decoding a string as forecasts")
+	}
+}
+
+func TestPrecipitationKeys(t *testing.T) {
+	// every probability key is present, only the one for the kind of
+	// forecast should be picked up
+	tests := map[string]func(Forecast) string{
+		"Day":   func(f Forecast) string { return f.Day.Precipitation },
+		"Night": func(f Forecast) string { return f.Night.Precipitation },
+		"540":   func(f Forecast) string { return f.Hourly.Precipitation },
+	}
+	want := map[string]string{"Day": "10", "Night": "20", "540": "30"}
+
+	for slot, get := range tests {
+		var f Forecast
+		body := `{"$": "` + slot + `", "PPd": "10", "PPn": "20", "Pp": "30"}`
+		if err := json.Unmarshal([]byte(body), &f); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := get(f); got != want[slot] {
+			t.Errorf("%s: expected a chance of rain of %s, got %q", slot, want[slot], got)
+		}
+	}
+
+	// the API describes each key as a probability, not an amount
+	for _, name := range []string{"fixtures/forecast_daily.json", "fixtures/forecast_3hourly.json"} {
+		body, err := fixtures.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var siteData SiteData
+		if err := json.Unmarshal(body, &siteData); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, param := range siteData.Site.MetaInfo.Params {
+			if strings.HasPrefix(param.Name, "P") && (param.Units != "%" || !strings.Contains(param.Description, "Probability")) {
+				t.Errorf("%s: expected %s to be a probability, got %+v", name, param.Name, param)
+			}
+		}
+	}
+}
+
+func TestUnmarshalRegionalForecast(t *testing.T) {
+	body, err := fixtures.ReadFile("fixtures/regional_forecast.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var regional RegionalForecast
+	err = json.Unmarshal(body, &regional)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	periods := regional.Forecast.Periods.Period
+	if len(periods) != 3 {
+		t.Fatalf("expected 3 periods, got %d", len(periods))
+	}
+
+	if len(periods[0].Paragraphs) != 4 || periods[0].Paragraphs[0].Title != "Headline:" {
+		t.Errorf("unexpected paragraphs %+v", periods[0].Paragraphs)
+	}
+
+	// a lone paragraph is an object rather than an array
+	if len(periods[1].Paragraphs) != 1 || periods[1].Paragraphs[0].Text == "" {
+		t.Errorf("expected a single paragraph, got %+v", periods[1].Paragraphs)
+	}
+}
+
+func TestFeelsLike(t *testing.T) {
+	// reference values from the Environment Canada wind chill and US
+	// National Weather Service heat index charts, which are given in
+	// units that don't convert exactly so allow a degree either way
+	tests := []struct {
+		name                       string
+		temp, wind, humidity, want int
+	}{
+		{"wind chill -10°C 20km/h", -10, 12, 80, -18},
+		{"wind chill 0°C 30km/h", 0, 19, 80, -7},
+		{"wind chill -20°C 40km/h", -20, 25, 80, -33},
+		{"heat index 90°F 70%", 32, 5, 70, 41},
+		{"heat index 86°F 50%", 30, 5, 50, 32},
+		{"heat index 95°F 40%", 35, 5, 40, 38},
+		{"calm and cold", 5, 2, 80, 5},
+		{"mild", 15, 20, 60, 15},
+		{"hot but dry", 30, 10, 20, 30},
+	}
+
+	for _, test := range tests {
+		got := FeelsLike(test.temp, test.wind, test.humidity)
+		if got < test.want-1 || got > test.want+1 {
+			t.Errorf("%s: got %d°C, want %d°C", test.name, got, test.want)
+		}
+	}
+}
+
+func TestUnmarshalWarnings(t *testing.T) {
+	body, err := fixtures.ReadFile("fixtures/warnings.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var feed WarningsFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(feed.Items) != 2 || feed.Items[1].Title != "Amber warning of wind affecting Yorkshire & Humber" {
+		t.Errorf("unexpected warnings %+v", feed.Items)
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	var used []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+		used = append(used, key)
+		if key != "third" {
+			w.Header().Set("Retry-After", "120")
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, "{}")
+	}))
+	defer ts.Close()
+
+	client := NewClient()
+	client.Limiter = nil
+	client.SetKeys([]string{"first", "second", "third"})
+
+	if _, err := client.Get(context.Background(), ts.URL+"?key=first&res=daily"); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(used) != "[first second third]" {
+		t.Errorf("expected each key to be tried in turn, got %v", used)
+	}
+
+	// rate limited keys aren't tried again until they reset
+	used = nil
+	if _, err := client.Get(context.Background(), ts.URL+"?key=first"); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(used) != "[third]" {
+		t.Errorf("expected only the working key to be used, got %v", used)
+	}
+}
+
+func TestKeysExhausted(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	client := NewClient()
+	client.Backoff = time.Millisecond
+	client.Limiter = nil
+	client.SetKeys([]string{"first", "second"})
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	client.keys.now = func() time.Time { return now }
+
+	_, err := client.Get(context.Background(), ts.URL+"?key=first")
+
+	var exhaustedErr *KeysExhaustedError
+	if !errors.As(err, &exhaustedErr) {
+		t.Fatalf("expected every key to be exhausted, got %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("expected one request for each key, got %d", requests)
+	}
+	if want := now.Add(defaultRateLimitWait); !exhaustedErr.Until.Equal(want) {
+		t.Errorf("expected the keys to reset at %v, got %v", want, exhaustedErr.Until)
+	}
+
+	// the first key comes back once it's rested
+	now = now.Add(defaultRateLimitWait)
+	if key, err := client.keys.next(); err != nil || key != "first" {
+		t.Errorf("expected the first key to be available again, got %q and %v", key, err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]time.Duration{
+		"30":                            30 * time.Second,
+		"Wed, 10 Jan 2024 12:05:00 GMT": 5 * time.Minute,
+		"":                              0,
+		"soon":                          0,
+	}
+
+	for header, want := range tests {
+		if got := parseRetryAfter(header, now); got != want {
+			t.Errorf("%q: got %v, want %v", header, got, want)
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(60)
+	limiter.now = func() time.Time { return now }
+
+	// a burst of requests are spaced a second apart
+	for i, want := range []time.Duration{0, time.Second, 2 * time.Second} {
+		if got := limiter.reserve(); got != want {
+			t.Errorf("request %d: got a wait of %v, want %v", i, got, want)
+		}
+	}
+
+	// slots left unused aren't saved up for later
+	now = now.Add(time.Minute)
+	for i, want := range []time.Duration{0, time.Second} {
+		if got := limiter.reserve(); got != want {
+			t.Errorf("request %d after a minute: got a wait of %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	limiter := NewLimiter(60)
+	limiter.now = func() time.Time { return now }
+
+	var waited []time.Duration
+	blocked := make(chan struct{}, 2)
+	fire := make(chan time.Time)
+	limiter.after = func(d time.Duration) <-chan time.Time {
+		waited = append(waited, d)
+		blocked <- struct{}{}
+		return fire
+	}
+
+	if err := limiter.Wait(context.Background()); err != nil || len(waited) != 0 {
+		t.Fatalf("expected the first request to go straight away, got %v and waits %v", err, waited)
+	}
+	if limiter.Waiting() {
+		t.Error("expected a request that went straight away not to count as waiting")
+	}
+
+	done := make(chan error)
+	go func() { done <- limiter.Wait(context.Background()) }()
+	<-blocked
+	if !limiter.Waiting() {
+		t.Error("expected the held up request to count as waiting")
+	}
+	fire <- now
+	if err := <-done; err != nil || len(waited) != 1 || waited[0] != time.Second {
+		t.Errorf("expected to wait 1s, got %v and waits %v", err, waited)
+	}
+	if limiter.Waiting() {
+		t.Error("expected nothing waiting once the request went")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled wait to give up, got %v", err)
+	}
+}
+
+func TestClientThrottlesDataPoint(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "{}")
+	}))
+	defer ts.Close()
+
+	client := NewClient()
+	client.Limiter = NewLimiter(1)
+
+	// other endpoints like the warnings feed aren't held up
+	for i := 0; i < 2; i++ {
+		if _, err := client.Get(context.Background(), ts.URL); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := client.Get(context.Background(), ts.URL+"?key=secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.Get(ctx, ts.URL+"?key=secret"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the second DataPoint request to be held back, got %v", err)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	if desc := Describe("1", Welsh); desc != "Diwrnod heulog" {
+		t.Errorf("expected the Welsh description, got %q", desc)
+	}
+	if desc := Describe("1", English); desc != "Sunny day" {
+		t.Errorf("expected the English description, got %q", desc)
+	}
+
+	// missing translations and languages fall back to English
+	translations["xx"] = map[string]string{"1": "Sunny"}
+	defer delete(translations, "xx")
+	if desc := Describe("30", "xx"); desc != "Thunder" {
+		t.Errorf("expected to fall back to English, got %q", desc)
+	}
+	if desc := Describe("30", "fr"); desc != "Thunder" {
+		t.Errorf("expected an unknown language to fall back to English, got %q", desc)
+	}
+	if desc := Describe("99", Welsh); desc != "" {
+		t.Errorf("expected nothing for an unknown code, got %q", desc)
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	// a language needn't translate every code, but the ones it does
+	// should be real and not left blank
+	for lang, descriptions := range translations {
+		for code, desc := range descriptions {
+			if _, ok := WeatherCodes[code]; !ok {
+				t.Errorf("%s describes unknown weather code %s", lang, code)
+			}
+			if desc == "" {
+				t.Errorf("%s has an empty description for weather code %s", lang, code)
+			}
+		}
+	}
+
+	if len(translations[Welsh]) != len(WeatherCodes) {
+		t.Errorf("expected Welsh to cover every code, got %d of %d", len(translations[Welsh]), len(WeatherCodes))
+	}
+
+	if lang, ok := ParseLanguage("CY"); !ok || lang != Welsh {
+		t.Errorf("expected CY to be Welsh, got %q", lang)
+	}
+	if _, ok := ParseLanguage("fr"); ok {
+		t.Error("expected French to be unsupported")
+	}
+}
+
+func TestFetchGzip(t *testing.T) {
+	fakeResponseBody := `{"fake json string"}`
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	fmt.Fprint(writer, fakeResponseBody)
+	writer.Close()
+
+	for _, header := range []string{"gzip", ""} {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Accept-Encoding") != "gzip" {
+				t.Errorf("expected gzip to be accepted, got %q", r.Header.Get("Accept-Encoding"))
+			}
+			// a proxy might not say it compressed the body
+			if header != "" {
+				w.Header().Set("Content-Encoding", header)
+			}
+			w.Write(compressed.Bytes())
+		}))
+
+		body := Fetch(ts.URL)
+		ts.Close()
+
+		if !bytes.Equal(body, []byte(fakeResponseBody)) {
+			t.Errorf("Content-Encoding %q: expected the decompressed body, got %q", header, body)
+		}
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	breaker := NewBreaker(3, time.Minute, 30*time.Second)
+	breaker.now = func() time.Time { return now }
+
+	// failures spread over more than the window don't trip it
+	breaker.Failed()
+	breaker.Failed()
+	now = now.Add(2 * time.Minute)
+	breaker.Failed()
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("expected the breaker to stay closed, got %v", err)
+	}
+
+	breaker.Failed()
+	breaker.Failed()
+	var openErr *BreakerOpenError
+	if err := breaker.Allow(); !errors.As(err, &openErr) || !openErr.Until.Equal(now.Add(30*time.Second)) {
+		t.Fatalf("expected the breaker to open for the cooldown, got %v", err)
+	}
+
+	// a failure once the cooldown is over reopens it straight away
+	now = now.Add(30 * time.Second)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("expected a request to be let through after the cooldown, got %v", err)
+	}
+	breaker.Failed()
+	if _, open := breaker.Open(); !open {
+		t.Fatal("expected one more failure to reopen the breaker")
+	}
+
+	now = now.Add(30 * time.Second)
+	breaker.Succeeded()
+	breaker.Failed()
+	if _, open := breaker.Open(); open {
+		t.Error("expected a success to close the breaker")
+	}
+}
+
+func TestClientBreaker(t *testing.T) {
+	requests := 0
+	down := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if down {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	client := NewClient()
+	client.Backoff = time.Millisecond
+	client.Limiter = nil
+	client.Breaker = NewBreaker(3, time.Minute, 30*time.Second)
+	client.Breaker.now = func() time.Time { return now }
+
+	// only the DataPoint API, whose requests have a key, is covered
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get(context.Background(), ts.URL); err == nil {
+			t.Fatal("expected the outage to fail the request")
+		}
+	}
+	if _, open := client.Breaker.Open(); open {
+		t.Fatal("expected requests without a key to leave the breaker alone")
+	}
+
+	// each Get counts once however many attempts it took, so the third
+	// trips it and the fourth fails without a request
+	url := ts.URL + "?key=abc"
+	for i := 0; i < 3; i++ {
+		if _, err := client.Get(context.Background(), url); err == nil {
+			t.Fatal("expected the outage to fail the request")
+		}
+		if _, open := client.Breaker.Open(); open != (i == 2) {
+			t.Fatalf("expected the breaker open only after 3 failed requests, got %v after %d", open, i+1)
+		}
+	}
+	requests = 0
+	_, err := client.Get(context.Background(), url)
+	var openErr *BreakerOpenError
+	if !errors.As(err, &openErr) || requests != 0 {
+		t.Fatalf("expected to fail fast without a request, got %v after %d", err, requests)
+	}
+
+	down = false
+	now = now.Add(30 * time.Second)
+	if _, err := client.Get(context.Background(), url); err != nil {
+		t.Fatalf("expected the service to be tried again after the cooldown, got %v", err)
+	}
+	if _, open := client.Breaker.Open(); open {
+		t.Error("expected the breaker to close once the service is back")
+	}
+}
+
+func TestClientMetrics(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	client := NewClient()
+	client.Backoff = time.Millisecond
+
+	// left unset nothing is counted, and nothing breaks
+	if _, err := client.Get(context.Background(), ts.URL); err != nil {
+		t.Fatal(err)
+	}
+	if counts := client.Metrics.Counts(); counts != (MetricCounts{}) {
+		t.Errorf("expected nothing counted without metrics, got %+v", counts)
+	}
+
+	requests = 0
+	client.Metrics = &Metrics{}
+	if _, err := client.Get(context.Background(), ts.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := client.Metrics.Counts()
+	if counts.Requests != 2 || counts.Failures != 1 || counts.Retries != 1 {
+		t.Errorf("expected 2 requests with 1 failure and 1 retry, got %+v", counts)
+	}
+	if counts.FetchTime <= 0 || counts.SlowestFetch > counts.FetchTime {
+		t.Errorf("expected the fetches to be timed, got %+v", counts)
+	}
+
+	var b strings.Builder
+	if err := client.Metrics.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# TYPE forecast_requests_total counter\n", "forecast_requests_total 2\n", "forecast_fetch_seconds_max "} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("expected %q in the metrics, got:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestClientProgress(t *testing.T) {
+	chunk := strings.Repeat("x", 1000)
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Length", "4000")
+
+		for i := 0; i < 4; i++ {
+			w.Write([]byte(chunk))
+			w.(http.Flusher).Flush()
+
+			// the first download is cut off part way through
+			if requests == 1 && i == 1 {
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+				return
+			}
+
+			time.Sleep(5 * time.Millisecond)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient()
+	client.Backoff = time.Millisecond
+
+	var calls [][2]int64
+	ctx := WithProgress(context.Background(), func(read, total int64) {
+		calls = append(calls, [2]int64{read, total})
+	})
+
+	body, err := client.Get(ctx, ts.URL)
+	if err != nil || len(body) != 4000 || requests != 2 {
+		t.Fatalf("expected the download to be retried in full, got %d bytes after %d requests and %v", len(body), requests, err)
+	}
+
+	// each attempt starts from nothing, the retry reading to the end
+	restarts := 0
+	for i, call := range calls {
+		if call[1] != 4000 {
+			t.Errorf("expected a total of 4000, got %v", call)
+		}
+		if call[0] == 0 {
+			restarts++
+		} else if call[0] < calls[i-1][0] {
+			t.Errorf("expected progress to only go forward within an attempt, got %v", calls)
+		}
+	}
+	if restarts != 2 || len(calls) < 4 || calls[len(calls)-1] != [2]int64{4000, 4000} {
+		t.Errorf("expected progress through two attempts, got %v", calls)
+	}
+
+	// nothing is reported unless asked for
+	if ProgressFrom(context.Background()) != nil {
+		t.Error("expected no progress without WithProgress")
+	}
+}
+
+func TestClientSlowDownload(t *testing.T) {
+	stall := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// a long download that keeps arriving, or one that stops
+		for i := 0; i < 5; i++ {
+			w.Write([]byte("x"))
+			w.(http.Flusher).Flush()
+
+			if stall && i == 1 {
+				time.Sleep(200 * time.Millisecond)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient()
+	client.Retries = 0
+	client.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	body, err := client.Get(context.Background(), ts.URL)
+	if err != nil || string(body) != "xxxxx" {
+		t.Fatalf("expected the whole body, got %q and %v", body, err)
+	}
+	if took := time.Since(start); took < client.Timeout {
+		t.Fatalf("expected the download to take longer than the timeout, took %v", took)
+	}
+
+	stall = true
+	if _, err := client.Get(context.Background(), ts.URL); !errors.Is(err, errIdle) {
+		t.Errorf("expected a server that goes quiet to time out, got %v", err)
+	}
+}
+
+func TestClientCACert(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer ts.Close()
+
+	client := NewClient()
+	client.Retries = 0
+
+	// the test server's certificate is signed by nobody the system trusts
+	if _, err := client.Get(context.Background(), ts.URL); err == nil {
+		t.Fatal("expected an unknown CA to be rejected")
+	}
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := os.WriteFile(path, cert, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.AddCACert(path); err != nil {
+		t.Fatal(err)
+	}
+	if body, err := client.Get(context.Background(), ts.URL); err != nil || string(body) != "{}" {
+		t.Errorf("expected the CA to be trusted, got %q and %v", body, err)
+	}
+
+	// proxies from the environment are still used
+	if transport := client.HTTPClient.Transport.(*http.Transport); transport.Proxy == nil {
+		t.Error("expected the proxy settings to be kept")
+	}
+
+	notPEM := filepath.Join(t.TempDir(), "ca.txt")
+	os.WriteFile(notPEM, []byte("not a certificate"), 0o644)
+	for _, path := range []string{notPEM, filepath.Join(t.TempDir(), "missing.pem")} {
+		if err := client.AddCACert(path); err == nil {
+			t.Errorf("%s: expected an error", path)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package data
+
+import (
+	"slices"
+	"strings"
+)
+
+// Language is an ISO 639-1 code
+type Language string
+
+const (
+	English Language = "en"
+	Welsh   Language = "cy"
+)
+
+// the weather code descriptions in languages other than English, which
+// are WeatherCodes, codes missing from a language fall back to English
+var translations = map[Language]map[string]string{
+	Welsh: {
+		"0":  "Noson glir",
+		"1":  "Diwrnod heulog",
+		"2":  "Rhannol gymylog (nos)",
+		"3":  "Rhannol gymylog (dydd)",
+		"4":  "Heb ei ddefnyddio",
+		"5":  "Niwlen",
+		"6":  "Niwl",
+		"7":  "Cymylog",
+		"8":  "Cymylau trwchus",
+		"9":  "Cawod ysgafn o law (nos)",
+		"10": "Cawod ysgafn o law (dydd)",
+		"11": "Glaw mân",
+		"12": "Glaw ysgafn",
+		"13": "Cawod drom o law (nos)",
+		"14": "Cawod drom o law (dydd)",
+		"15": "Glaw trwm",
+		"16": "Cawod eirlaw (nos)",
+		"17": "Cawod eirlaw (dydd)",
+		"18": "Eirlaw",
+		"19": "Cawod cenllysg (nos)",
+		"20": "Cawod cenllysg (dydd)",
+		"21": "Cenllysg",
+		"22": "Cawod ysgafn o eira (nos)",
+		"23": "Cawod ysgafn o eira (dydd)",
+		"24": "Eira ysgafn",
+		"25": "Cawod drom o eira (nos)",
+		"26": "Cawod drom o eira (dydd)",
+		"27": "Eira trwm",
+		"28": "Cawod daranau (nos)",
+		"29": "Cawod daranau (dydd)",
+		"30": "Taranau",
+	},
+}
+
+// Languages lists the languages descriptions are available in, English
+// first
+func Languages() []Language {
+	languages := []Language{English}
+	for lang := range translations {
+		languages = append(languages, lang)
+	}
+	slices.Sort(languages[1:])
+
+	return languages
+}
+
+// ParseLanguage finds a supported language by its code, ignoring case
+func ParseLanguage(code string) (Language, bool) {
+	for _, lang := range Languages() {
+		if strings.EqualFold(string(lang), code) {
+			return lang, true
+		}
+	}
+
+	return "", false
+}
+
+// Describe is the description of a weather code in lang, or English if
+// there's no translation, empty for an unknown code
+func Describe(code string, lang Language) string {
+	if desc, ok := translations[lang][code]; ok {
+		return desc
+	}
+
+	return WeatherCodes[code]
+}
@@ -0,0 +1,39 @@
+package data
+
+import "math"
+
+// FeelsLike estimates the feels like temperature in °C, for when the API
+// doesn't give one. Cold, windy conditions use the wind chill index and
+// hot, humid ones the heat index, otherwise it's the air temperature.
+func FeelsLike(tempC, windMph, humidityPct int) int {
+	switch {
+	case tempC <= 10 && windMph > 3:
+		return windChill(tempC, windMph)
+	case tempC >= 27 && humidityPct >= 40:
+		return heatIndex(tempC, humidityPct)
+	default:
+		return tempC
+	}
+}
+
+// the wind chill index used by the Met Office and others, which works
+// in km/h
+func windChill(tempC, windMph int) int {
+	t := float64(tempC)
+	v := math.Pow(float64(windMph)*1.609344, 0.16)
+
+	return int(math.Round(13.12 + 0.6215*t - 11.37*v + 0.3965*t*v))
+}
+
+// the US National Weather Service's Rothfusz regression, which works
+// in °F
+func heatIndex(tempC, humidityPct int) int {
+	t := float64(tempC)*9/5 + 32
+	rh := float64(humidityPct)
+
+	hi := -42.379 + 2.04901523*t + 10.14333127*rh -
+		0.22475541*t*rh - 0.00683783*t*t - 0.05481717*rh*rh +
+		0.00122874*t*t*rh + 0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+
+	return int(math.Round((hi - 32) * 5 / 9))
+}
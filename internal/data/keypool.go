@@ -0,0 +1,114 @@
+package data
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// how long to rest a key turned away without a Retry-After header
+const defaultRateLimitWait = time.Minute
+
+// KeysExhaustedError is returned when every API key is rate limited
+type KeysExhaustedError struct {
+	// when the first key can be used again
+	Until time.Time
+}
+
+func (e *KeysExhaustedError) Error() string {
+	return "every API key is rate limited until " + e.Until.Format("15:04:05")
+}
+
+// keyPool hands out API keys in order, skipping any that are resting
+// after a 429 Too Many Requests response
+type keyPool struct {
+	mu   sync.Mutex
+	keys []string
+	// when each rate limited key can be used again
+	limited map[string]time.Time
+	now     func() time.Time
+}
+
+func newKeyPool(keys []string) *keyPool {
+	return &keyPool{
+		keys:    keys,
+		limited: map[string]time.Time{},
+		now:     time.Now,
+	}
+}
+
+// the first key that isn't rate limited, so the first key is used again
+// as soon as it resets
+func (p *keyPool) next() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+
+	var soonest time.Time
+	for _, key := range p.keys {
+		until, ok := p.limited[key]
+		if !ok || !now.Before(until) {
+			delete(p.limited, key)
+			return key, nil
+		}
+
+		if soonest.IsZero() || until.Before(soonest) {
+			soonest = until
+		}
+	}
+
+	return "", &KeysExhaustedError{Until: soonest}
+}
+
+// rest key for wait, or the default if the response didn't say
+func (p *keyPool) limit(key string, wait time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if wait <= 0 {
+		wait = defaultRateLimitWait
+	}
+
+	p.limited[key] = p.now().Add(wait)
+}
+
+// the url with its key query parameter swapped for key, urls without
+// one aren't for the DataPoint API and are left alone
+func withKey(rawUrl string, key string) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", fmt.Errorf("error parsing url: %w", err)
+	}
+
+	query := u.Query()
+	if !query.Has("key") {
+		return rawUrl, nil
+	}
+
+	query.Set("key", key)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+func hasKey(rawUrl string) bool {
+	u, err := url.Parse(rawUrl)
+	return err == nil && u.Query().Has("key")
+}
+
+// a Retry-After header is either a number of seconds or a date
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		return date.Sub(now)
+	}
+
+	return 0
+}
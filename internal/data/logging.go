@@ -0,0 +1,11 @@
+package data
+
+import "regexp"
+
+// API keys are passed as a query parameter, so keep them out of the logs
+var keyPattern = regexp.MustCompile(`key=[^&\s"]+`)
+
+// Redact hides any API key in s
+func Redact(s string) string {
+	return keyPattern.ReplaceAllString(s, "key=REDACTED")
+}
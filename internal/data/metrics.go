@@ -0,0 +1,104 @@
+package data
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// MetricCounts is a snapshot of what a Metrics has recorded
+type MetricCounts struct {
+	Requests int
+	// requests without a response, or with a status other than 200 OK
+	Failures int
+	Retries  int
+	// 429 Too Many Requests responses
+	RateLimited int
+	// requests turned away by an open Breaker without being made
+	Rejected     int
+	CacheHits    int
+	CacheMisses  int
+	FetchTime    time.Duration
+	SlowestFetch time.Duration
+}
+
+// Metrics counts a run's requests and how long they took, every method
+// does nothing on a nil *Metrics so leaving it unset costs nothing
+type Metrics struct {
+	mu     sync.Mutex
+	counts MetricCounts
+}
+
+func (m *Metrics) record(update func(*MetricCounts)) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	update(&m.counts)
+}
+
+func (m *Metrics) fetched(took time.Duration, err error) {
+	m.record(func(c *MetricCounts) {
+		c.Requests++
+		c.FetchTime += took
+		c.SlowestFetch = max(c.SlowestFetch, took)
+		if err != nil {
+			c.Failures++
+		}
+	})
+}
+
+func (m *Metrics) retried()     { m.record(func(c *MetricCounts) { c.Retries++ }) }
+func (m *Metrics) rateLimited() { m.record(func(c *MetricCounts) { c.RateLimited++ }) }
+func (m *Metrics) rejected()    { m.record(func(c *MetricCounts) { c.Rejected++ }) }
+
+// CacheHit counts data reused instead of being fetched again
+func (m *Metrics) CacheHit() { m.record(func(c *MetricCounts) { c.CacheHits++ }) }
+
+// CacheMiss counts data that had to be fetched as none was kept
+func (m *Metrics) CacheMiss() { m.record(func(c *MetricCounts) { c.CacheMisses++ }) }
+
+func (m *Metrics) Counts() MetricCounts {
+	if m == nil {
+		return MetricCounts{}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.counts
+}
+
+// Write the counts to w in the Prometheus text format
+func (m *Metrics) Write(w io.Writer) error {
+	c := m.Counts()
+
+	metrics := []struct {
+		name, kind, help string
+		value            float64
+	}{
+		{"forecast_requests_total", "counter", "HTTP requests made.", float64(c.Requests)},
+		{"forecast_request_failures_total", "counter", "HTTP requests that failed.", float64(c.Failures)},
+		{"forecast_retries_total", "counter", "Requests tried again after failing.", float64(c.Retries)},
+		{"forecast_rate_limited_total", "counter", "Responses turning away a rate limited API key.", float64(c.RateLimited)},
+		{"forecast_rejected_total", "counter", "Requests not made while the service appeared to be down.", float64(c.Rejected)},
+		{"forecast_cache_hits_total", "counter", "Data reused rather than fetched.", float64(c.CacheHits)},
+		{"forecast_cache_misses_total", "counter", "Data fetched as none was kept.", float64(c.CacheMisses)},
+		{"forecast_fetch_seconds_total", "counter", "Time spent on HTTP requests.", c.FetchTime.Seconds()},
+		{"forecast_fetch_seconds_max", "gauge", "The slowest HTTP request.", c.SlowestFetch.Seconds()},
+	}
+
+	for _, metric := range metrics {
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %g\n",
+			metric.name, metric.help, metric.name, metric.kind, metric.name, metric.value)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+//go:embed fixtures/*.json fixtures/*.xml
+var fixtures embed.FS
+
+// Source fetches the body of a url, letting data come from somewhere
+// other than the network
+type Source interface {
+	Get(ctx context.Context, url string) ([]byte, error)
+}
+
+// SourceFunc lets an ordinary function be used as a Source, such as a
+// stub serving canned responses in tests
+type SourceFunc func(ctx context.Context, url string) ([]byte, error)
+
+func (f SourceFunc) Get(ctx context.Context, url string) ([]byte, error) {
+	return f(ctx, url)
+}
+
+// DefaultSource is used by Fetch and FetchContext
+var DefaultSource Source = DefaultClient
+
+// Offline serves bundled sample data in place of the DataPoint API,
+// every site shares the same sample forecast
+type Offline struct{}
+
+func (Offline) Get(ctx context.Context, rawUrl string) ([]byte, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing url: %w", err)
+	}
+
+	var name string
+
+	switch {
+	case strings.HasSuffix(u.Path, "wxfcs/all/json/sitelist"):
+		name = "sitelist.json"
+	case strings.HasSuffix(u.Path, "wxobs/all/json/sitelist"):
+		name = "observation_sitelist.json"
+	case strings.Contains(u.Path, "WarningsRSS/Region/"):
+		name = "warnings.xml"
+	case strings.Contains(u.Path, "txt/wxfcs/regionalforecast/json/"):
+		name = "regional_forecast.json"
+	case strings.HasSuffix(u.Path, "wxfcs/all/json/capabilities"):
+		name = "capabilities_" + u.Query().Get("res") + ".json"
+	case strings.Contains(u.Path, "wxobs/all/json/"):
+		name = "observations.json"
+	case strings.Contains(u.Path, "wxfcs/all/json/") && u.Query().Get("res") == "3hourly":
+		name = "forecast_3hourly.json"
+	case strings.Contains(u.Path, "wxfcs/all/json/"):
+		name = "forecast_daily.json"
+	default:
+		return nil, fmt.Errorf("no offline data for %s", u.Path)
+	}
+
+	return fixtures.ReadFile("fixtures/" + name)
+}
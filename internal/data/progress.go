@@ -0,0 +1,42 @@
+package data
+
+import (
+	"context"
+	"io"
+)
+
+// Progress is told how much of a response has been read as it arrives,
+// total is -1 when the length isn't known. A retried request starts
+// again from 0
+type Progress func(read, total int64)
+
+type progressKey struct{}
+
+// WithProgress asks for the progress of requests made with ctx, for
+// large downloads like the sitelist
+func WithProgress(ctx context.Context, progress Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, progress)
+}
+
+// ProgressFrom returns the Progress set with WithProgress, or nil
+func ProgressFrom(ctx context.Context) Progress {
+	progress, _ := ctx.Value(progressKey{}).(Progress)
+	return progress
+}
+
+// counts the bytes read through it
+type progressReader struct {
+	reader      io.Reader
+	read, total int64
+	progress    Progress
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.read += int64(n)
+		r.progress(r.read, r.total)
+	}
+
+	return n, err
+}
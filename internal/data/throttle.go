@@ -0,0 +1,82 @@
+package data
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// the DataPoint free tier allows 100 calls a minute
+const DefaultRequestsPerMinute = 100
+
+// Limiter spaces requests out evenly so they can't burst past a rate
+// limit, a request that comes too soon after the last waits its turn
+type Limiter struct {
+	interval time.Duration
+
+	mu sync.Mutex
+	// when the next request may be made
+	next time.Time
+	// how many requests are held up waiting for their slot
+	waiting int
+
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+func NewLimiter(perMinute int) *Limiter {
+	return &Limiter{
+		interval: time.Minute / time.Duration(max(1, perMinute)),
+		now:      time.Now,
+		after:    time.After,
+	}
+}
+
+// take the next free slot, returning how long until it comes round
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+
+	return slot.Sub(now)
+}
+
+// Waiting reports whether any request is being held up right now, a
+// request that got a free slot doesn't count
+func (l *Limiter) Waiting() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.waiting > 0
+}
+
+// Wait blocks until a request can be made, or ctx is cancelled
+func (l *Limiter) Wait(ctx context.Context) error {
+	wait := l.reserve()
+	if wait <= 0 {
+		return nil
+	}
+
+	l.setWaiting(1)
+	defer l.setWaiting(-1)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.after(wait):
+		return nil
+	}
+}
+
+func (l *Limiter) setWaiting(change int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.waiting += change
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+// somewhere out of the way of the TUI, which anything printed would corrupt
+func defaultLogPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "forecast", "forecast.log")
+}
+
+// errors from requests include their url, and with it the API key
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() == slog.KindAny || a.Value.Kind() == slog.KindString {
+		return slog.String(a.Key, data.Redact(a.Value.String()))
+	}
+
+	return a
+}
+
+// send logs to the file at path, debug messages included when verbose,
+// the returned closer should be closed on exit
+func setupLogging(path string, verbose bool) (io.Closer, error) {
+	level := slog.LevelInfo
+	if verbose {
+		level = slog.LevelDebug
+	}
+
+	// nothing is logged if there's nowhere to put it
+	if path == "" {
+		slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		return io.NopCloser(nil), nil
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("could not create log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open log file: %w", err)
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	})))
+
+	return f, nil
+}
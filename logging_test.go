@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetupLogging(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+
+	path := filepath.Join(t.TempDir(), "logs", "forecast.log")
+	closer, err := setupLogging(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slog.Debug("hidden without -verbose")
+	slog.Error("fetching site data", "err", errors.New(`Get "http://example.com/sitelist?key=secret&res=daily": timeout`))
+	closer.Close()
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(body)
+
+	if !strings.Contains(log, "fetching site data") || strings.Contains(log, "hidden") {
+		t.Errorf("expected only the error to be logged, got:\n%s", log)
+	}
+
+	if strings.Contains(log, "secret") || !strings.Contains(log, "key=REDACTED") {
+		t.Errorf("expected the API key to be redacted, got:\n%s", log)
+	}
+
+	closer, err = setupLogging(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slog.Debug("shown with -verbose")
+	closer.Close()
+
+	if body, _ := os.ReadFile(path); !strings.Contains(string(body), "shown with -verbose") {
+		t.Errorf("expected debug messages when verbose, got:\n%s", body)
+	}
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
+	"net/http"
 	"os"
 	"slices"
 	"sort"
@@ -11,9 +16,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jasonleelunn/forecast/internal/data"
@@ -21,24 +29,155 @@ import (
 )
 
 type model struct {
-	width              int
-	height             int
-	err                error
-	textInput          textinput.Model
-	table              table.Model
+	width     int
+	height    int
+	err       error
+	textInput textinput.Model
+	table     table.Model
+	// every site from the sitelist, sorted by name
+	allRows    Rows
+	placenames []string
+	siteCoords map[string]coordinates
+	// the search results before being fitted to the table's columns
+	tableRows          Rows
 	list               list.Model
 	siteData           data.SiteData
+	regionFilter       string
+	sortColumn         int
+	sortDescending     bool
 	locationChosen     bool
 	locationId         string
 	forecastResolution resolution
 	forecastChosen     bool
-	forecastData       forecastData
+	summaryChosen      bool
+	summaryOffset      int
+	// how many days of forecasts to list, 0 for all of them
+	maxDays        int
+	regionalChosen bool
+	regionalText   string
+	regional       viewport.Model
+	// the most matches a search lists and how loose they can be
+	searchResults  int
+	searchDistance int
+	// the 3hourly breakdown of one period
+	dayChosen bool
+	dayPeriod int
+	dayTable  table.Model
+	// a location pinned to compare against the one being viewed
+	compareId         string
+	compareData       data.SiteData
+	compareResolution resolution
+	comparing         bool
+	compareOffset     int
+	forecastData      forecastData
+	observing         bool
+	observationSites  map[string]bool
+	notice            string
+	// most recent first, historyPosition is 1-based while recalling
+	searchHistory   []string
+	historyPosition int
+	themeIndex      int
+	tempUnit        temperatureUnit
+	windUnit        windUnit
+	twelveHour      bool
+	// label each forecast detail with the API params it came from
+	debug bool
+	// the forecast being viewed as it was when last opened, remembered
+	// in the file at seenPath, nil if it wasn't. changesKey is the seen
+	// forecast being looked up
+	seenBefore *forecastData
+	changesKey string
+	seenPath   string
+	// the settings screen, shown over the view it was opened from
+	settingsChosen bool
+	settings       list.Model
+	// show a second line of details for each forecast in the list
+	expanded bool
+	// show a day at a time instead of the list, and the day shown
+	carousel     bool
+	carouselDate time.Time
+	// only list the forecasts for some days, e.g. today
+	dateFilter dateFilter
+	language   data.Language
+	// site ids shown on the dashboard, in the order they were added
+	favourites      []string
+	dashboardChosen bool
+	dashboardCursor int
+	cards           map[string]dashboardCard
+	// the search result last highlighted, and any forecast fetched for
+	// it ahead of being chosen
+	highlightedId   string
+	prefetched      prefetchedSite
+	refreshInterval time.Duration
+	// counts the refreshes scheduled, the ticks of older ones are stale
+	refreshId   int
+	lastUpdated time.Time
+	loading     bool
+	// asking for an API key, before the sitelist can be fetched
+	enteringKey bool
+	checkingKey bool
+	keyInput    textinput.Model
+	// fetching the sitelist, before there's anything to search
+	loadingSites bool
+	// bytes of the sitelist downloaded so far, the total is -1 if the
+	// size isn't known and 0 before it starts
+	sitelistRead  int64
+	sitelistTotal int64
+	spinner       spinner.Model
+	// the site to open once the sitelist arrives
+	startLocation string
+	// severe weather warnings for a region, kept for a few minutes
+	warnings        []Warning
+	warningsRegion  string
+	warningsFetched time.Time
+	// list the sites nearest the user's IP address on start
+	locateNearby bool
+	// incremented each time a location is left so that late responses
+	// and refresh ticks meant for it can be recognised and ignored
+	sessionId int
+	ctx       context.Context
+	cancel    context.CancelFunc
+	// where everything is downloaded from, tests give each model a stub
+	fetcher data.Source
+}
+
+type fetchReason int
+
+const (
+	fetchSelect fetchReason = iota
+	fetchToggle
+	fetchRefresh
+	// asked for by the user rather than on a timer
+	fetchRefreshNow
+)
+
+type siteDataMsg struct {
+	id         int
+	reason     fetchReason
+	siteData   data.SiteData
+	resolution resolution
+	observing  bool
+	err        error
+}
+
+type observationSitesMsg struct {
+	id    int
+	sites map[string]bool
+	err   error
+}
+
+type refreshTickMsg struct {
+	id int
+	// the scheduling it came from, only the latest is acted on
+	refresh int
 }
 
 type location struct {
-	Id     string `json:"id"`
-	Name   string `json:"name"`
-	Region string `json:"region"`
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Region    string `json:"region"`
+	Latitude  string `json:"latitude"`
+	Longitude string `json:"longitude"`
 }
 
 type locations struct {
@@ -48,20 +187,28 @@ type locations struct {
 type forecastItem struct {
 	title, desc                string
 	periodIndex, forecastIndex int
+	// the 3hourly slot under way, or the next one if today's are over
+	now bool
 }
 
+// values the API left out are omitted from the JSON
 type forecastData struct {
-	Time          string
-	WeatherCode   string
-	UV            string
-	WindDirection string
-	WindSpeed     string
-	Visibility    string
-	Precipitation string
-	Humidity      string
-	GustSpeed     string
-	Temperature   string
-	FeelsLikeTemp string
+	Time          string `json:"time,omitempty"`
+	WeatherCode   string `json:"weatherCode,omitempty"`
+	UV            string `json:"uv,omitempty"`
+	WindDirection string `json:"windDirection,omitempty"`
+	WindSpeed     string `json:"windSpeed,omitempty"`
+	Visibility    string `json:"visibility,omitempty"`
+	// the percentage chance, the API doesn't forecast amounts
+	Precipitation string `json:"precipitation,omitempty"`
+	Humidity      string `json:"humidity,omitempty"`
+	GustSpeed     string `json:"gustSpeed,omitempty"`
+	Temperature   string `json:"temperature,omitempty"`
+	FeelsLikeTemp string `json:"feelsLikeTemperature,omitempty"`
+	// only present in observations
+	Pressure         string `json:"pressure,omitempty"`
+	PressureTendency string `json:"pressureTendency,omitempty"`
+	DewPoint         string `json:"dewPoint,omitempty"`
 }
 
 func (i forecastItem) Title() string        { return i.title }
@@ -69,30 +216,162 @@ func (i forecastItem) Description() string  { return i.desc }
 func (i forecastItem) FilterValue() string  { return i.title }
 func (i forecastItem) Position() (int, int) { return i.periodIndex, i.forecastIndex }
 
+// the API sends every value as a string, these convert them to numbers
+func (f forecastData) Minutes() (int, error)          { return parseValue("time", f.Time) }
+func (f forecastData) UVIndex() (int, error)          { return parseValue("UV", f.UV) }
+func (f forecastData) WindSpeedMph() (int, error)     { return parseValue("wind speed", f.WindSpeed) }
+func (f forecastData) PrecipitationPct() (int, error) { return parseValue("rain", f.Precipitation) }
+func (f forecastData) HumidityPct() (int, error)      { return parseValue("humidity", f.Humidity) }
+func (f forecastData) GustSpeedMph() (int, error)     { return parseValue("gust speed", f.GustSpeed) }
+func (f forecastData) TemperatureC() (int, error)     { return parseValue("temperature", f.Temperature) }
+func (f forecastData) FeelsLikeC() (int, error)       { return parseValue("feels like", f.FeelsLikeTemp) }
+
+// observations have decimal values which are rounded to whole numbers
+func parseValue(name string, value string) (int, error) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid %s value %q", name, value)
+	}
+
+	return int(math.Round(n)), nil
+}
+
 type Rows []table.Row
 
 func (rows Rows) Len() int {
 	return len(rows)
 }
 
-func (rows Rows) Less(i, j int) bool {
-	// Sort by Name field alphabetically
-	return rows[i][0] < rows[j][0]
-}
-
 func (rows Rows) Swap(i, j int) {
 	rows[i], rows[j] = rows[j], rows[i]
 }
 
+// sorts Rows by one of their columns
+type rowOrder struct {
+	Rows
+	column     int
+	descending bool
+}
+
+func (o rowOrder) Less(i, j int) bool {
+	a, b := o.Rows[i][o.column], o.Rows[j][o.column]
+	if o.descending {
+		a, b = b, a
+	}
+
+	// IDs vary in length so compare them as numbers
+	x, errX := strconv.Atoi(a)
+	y, errY := strconv.Atoi(b)
+	if errX == nil && errY == nil {
+		return x < y
+	}
+
+	return a < b
+}
+
+// return a sorted copy, leaving the original order intact
+func sortRows(rows Rows, column int, descending bool) Rows {
+	sorted := slices.Clone(rows)
+	sort.Stable(rowOrder{Rows: sorted, column: column, descending: descending})
+
+	return sorted
+}
+
 type resolution string
 
+// columns of the search table
+const (
+	nameColumn = iota
+	idColumn
+	regionColumn
+	dataColumn
+)
+
+// the data column's markers for which kinds of data a site has, most
+// have forecasts only
+const (
+	forecastsOnly    = ""
+	withObservations = "+ obs"
+	observationsOnly = "obs only"
+)
+
+type temperatureUnit string
+
+type windUnit string
+
 type color int
 
 const (
 	baseUrl = "http://datapoint.metoffice.gov.uk/public/data/"
 
+	regionPrefix = "region:"
+	// go straight to a site by its id, e.g. id:310002
+	idPrefix = "id:"
+
+	maxSearchHistory = 20
+	// a short search matches hundreds of sites so only the closest are
+	// listed, the distance grows with the letters of a name that aren't
+	// in the search, e.g. "London Heathrow Airport" is 21 from "lon"
+	defaultSearchResults  = 50
+	defaultSearchDistance = 25
+
+	sitelistAttempts = 2
+
+	// below this width the search table is compacted
+	compactWidth = 70
+	minIdWidth   = 6
+	// the name column grows into wider terminals up to this
+	minNameWidth = 40
+	maxNameWidth = 60
+	// lines of the search view that aren't table rows, the input and
+	// notice above it, the table's border and header and the hint below
+	searchChrome = 9
+
+	// shown in place of values missing from the API's response
+	missingValue = "—"
+
+	noMatchesNotice = "No matching locations"
+
+	notUsedWeatherCode = "4"
+	unknownConditions  = "Unknown conditions"
+
+	// warm and humid air feels muggy, very low humidity feels dry
+	muggyTempThreshold     = 18
+	muggyHumidityThreshold = 70
+	dryHumidityThreshold   = 30
+
+	// gusts at or above this many mph get a warning
+	windyGustThreshold = 40
+	// winds of this Beaufort force and above are gales
+	galeForce = 8
+	// gusts this many mph or more above the wind are gusty, or squally
+	gustyGap   = 10
+	squallyGap = 20
+
+	defaultRefreshMinutes = 15
+	// a CA certificate to trust, for networks behind a re-signing proxy
+	caCertEnv = "FORECAST_CA_CERT"
+	// lines reserved above and below the list for indicators
+	headerHeight = 1
+	footerHeight = 2
+
+	// data older than this is highlighted as stale
+	staleDataThreshold = 6 * time.Hour
+
 	dailyResolution       resolution = "daily"
 	threeHourlyResolution resolution = "3hourly"
+	// observations are only available hourly
+	hourlyResolution resolution = "hourly"
+
+	celsiusUnit    temperatureUnit = "°C"
+	fahrenheitUnit temperatureUnit = "°F"
+
+	mphUnit   windUnit = "mph"
+	kphUnit   windUnit = "km/h"
+	knotsUnit windUnit = "kt"
+
+	// difference from the actual temperature (°C) worth pointing out
+	feelsLikeThreshold = 3
 )
 
 const (
@@ -104,50 +383,92 @@ const (
 	yellow
 	pink
 	purple
+	// subtle backgrounds for alternating rows
+	stripe
+	stripeAlt
+	// severe weather warning levels, after yellow
+	amber
+	red
 )
 
-var (
-	colorPalette = map[color]string{
-		black:  "#000",
-		white:  "#ffffff",
-		grey:   "#dddddf",
-		green:  "#98FF98",
-		blue:   "#a9def9",
-		yellow: "#fcf6bd",
-		pink:   "#ff99c8",
-		purple: "#e4c1f9",
-	}
+// temperature bands (°C) used to colour-code temperatures,
+// each threshold is the lowest temperature in that band
+const (
+	mildTempThreshold = 8
+	warmTempThreshold = 16
+	hotTempThreshold  = 23
+)
 
-	borderStyle = lipgloss.NewStyle().
-			BorderStyle(lipgloss.NormalBorder()).
-			BorderForeground(lipgloss.Color(colorPalette[blue]))
+const (
+	minBarWidth = 5
+	maxBarWidth = 30
+	// space reserved beside a bar for its label and value, e.g.
+	// "Humidity        " and " 63% (comfortable)"
+	barLabelWidth = 35
+	// the most the field codes widen the labels by in debug mode, e.g.
+	// " (S, Gn?)"
+	debugCodesWidth = 10
+)
+
+var (
+	// set from the active theme, see applyTheme
+	colorPalette map[color]string
+	borderStyle  lipgloss.Style
 
 	listStyle = lipgloss.NewStyle().Margin(1, 2)
 
+	// arrows point the way the wind is blowing, i.e. away from the
+	// compass direction it comes from, with the intermediate points
+	// rounded towards their nearest cardinal direction
+	windArrows = map[string]string{
+		"N":   "↓",
+		"NNE": "↓",
+		"NE":  "↙",
+		"ENE": "←",
+		"E":   "←",
+		"ESE": "←",
+		"SE":  "↖",
+		"SSE": "↑",
+		"S":   "↑",
+		"SSW": "↑",
+		"SW":  "↗",
+		"WSW": "→",
+		"W":   "→",
+		"WNW": "→",
+		"NW":  "↘",
+		"NNW": "↓",
+	}
+
+	// the points of the compass clockwise from north, 22.5° apart
+	compassPoints = []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+
 	tableStyle         table.Styles
 	tableStyleFocussed table.Styles
 
-	placenames []string
-	rows       Rows
-
 	apiKey string
-)
 
-func getApiKey() string {
-	apiKey, ok := os.LookupEnv("MET_OFFICE_API_KEY")
+	// the current time, fixed in tests so views render the same each run
+	clock = time.Now
+)
 
-	if !ok {
-		log.Fatal("MET_OFFICE_API_KEY env var not set")
-		os.Exit(1)
-	}
+// flatten Forecast JSON object returned by API into a consistent format
+func getForecastData(m model, f data.Forecast) forecastData {
+	return flattenForecast(m.forecastResolution, m.siteData.Site.MetaInfo, f)
+}
 
-	return apiKey
+func flattenForecast(res resolution, meta data.Meta, f data.Forecast) forecastData {
+	return withPlaceholders(forecastValues(res, meta, f))
 }
 
-// flatten Forecast JSON object returned by API into a consistent format
-func getForecastData(m model, f data.Forecast) forecastData {
-	if m.forecastResolution == dailyResolution && f.Time == "Day" {
-		return forecastData{
+// a forecast's values in °C and mph, with anything the API left out
+// left empty rather than marked as missing
+func forecastValues(res resolution, meta data.Meta, f data.Forecast) forecastData {
+	var fd forecastData
+	// the params holding each kind of value, to look up their units
+	codes := paramCodesFor(res, f.Time)
+
+	if res == dailyResolution && f.Time == "Day" {
+		fd = forecastData{
 			Time:          f.Time,
 			WeatherCode:   f.WeatherCode,
 			WindDirection: f.WindDirection,
@@ -160,8 +481,8 @@ func getForecastData(m model, f data.Forecast) forecastData {
 			Temperature:   f.Day.Temperature,
 			FeelsLikeTemp: f.Day.FeelsLikeTemp,
 		}
-	} else if m.forecastResolution == dailyResolution && f.Time == "Night" {
-		return forecastData{
+	} else if res == dailyResolution && f.Time == "Night" {
+		fd = forecastData{
 			Time:          f.Time,
 			WeatherCode:   f.WeatherCode,
 			WindDirection: f.WindDirection,
@@ -174,7 +495,7 @@ func getForecastData(m model, f data.Forecast) forecastData {
 			FeelsLikeTemp: f.Night.FeelsLikeTemp,
 		}
 	} else {
-		return forecastData{
+		fd = forecastData{
 			Time:          f.Time,
 			WeatherCode:   f.WeatherCode,
 			WindDirection: f.WindDirection,
@@ -186,378 +507,2986 @@ func getForecastData(m model, f data.Forecast) forecastData {
 			GustSpeed:     f.Hourly.GustSpeed,
 			Temperature:   f.Hourly.Temperature,
 			FeelsLikeTemp: f.Hourly.FeelsLikeTemp,
+
+			Pressure:         f.Observed.Pressure,
+			PressureTendency: f.Observed.PressureTendency,
+			DewPoint:         f.Observed.DewPoint,
 		}
 	}
+
+	fd.Temperature = toCelsius(fd.Temperature, paramUnit(meta, codes.temp))
+	fd.FeelsLikeTemp = toCelsius(fd.FeelsLikeTemp, paramUnit(meta, codes.feelsLike))
+	fd.DewPoint = toCelsius(fd.DewPoint, paramUnit(meta, "Dp"))
+	fd.WindSpeed = toMph(fd.WindSpeed, paramUnit(meta, "S"))
+	fd.GustSpeed = toMph(fd.GustSpeed, paramUnit(meta, codes.gust))
+
+	return withFeelsLike(fd)
 }
 
-func makeUrl(endpoint string, paramList ...string) string {
-	params := ""
-	for _, param := range paramList {
-		params += "&" + param
+// estimate the feels like temperature when the API leaves it out
+func withFeelsLike(fd forecastData) forecastData {
+	if fd.FeelsLikeTemp != "" {
+		return fd
 	}
 
-	return baseUrl + endpoint + "?key=" + apiKey + params
+	temp, errTemp := fd.TemperatureC()
+	wind, errWind := fd.WindSpeedMph()
+	humidity, errHumidity := fd.HumidityPct()
+	if errTemp == nil && errWind == nil && errHumidity == nil {
+		fd.FeelsLikeTemp = strconv.Itoa(data.FeelsLike(temp, wind, humidity))
+	}
+
+	return fd
 }
 
-func extractRows(body []byte) Rows {
-	var data struct {
-		Locations locations `json:"locations"`
+// values every kind of forecast should have are marked as missing
+// when the API leaves them out, the rest are legitimately optional
+func withPlaceholders(fd forecastData) forecastData {
+	for _, value := range []*string{
+		&fd.Time,
+		&fd.WindDirection,
+		&fd.WindSpeed,
+		&fd.Visibility,
+		&fd.Humidity,
+		&fd.GustSpeed,
+		&fd.Temperature,
+	} {
+		if strings.TrimSpace(*value) == "" {
+			*value = missingValue
+		}
 	}
 
-	err := json.Unmarshal(body, &data)
-	if err != nil {
-		fmt.Println("Error decoding JSON:", err)
-		return nil
-	}
+	return fd
+}
 
-	for _, location := range data.Locations.Location {
-		placenames = append(placenames, location.Name)
-		rows = append(rows, table.Row{location.Name, location.Id, location.Region})
+// look up a weather code's description in lang, codes the API marks as
+// "Not used" or that fall outside the documented range are unknown
+func describeCode(code string, lang data.Language) string {
+	desc := data.Describe(code, lang)
+	if desc == "" || code == notUsedWeatherCode {
+		return unknownConditions
 	}
 
-	slices.Sort(placenames)
-	sort.Sort(rows)
+	return desc
+}
 
-	return rows
+// pick a palette colour for a temperature based on its band
+func tempColor(celsius string) lipgloss.TerminalColor {
+	return paletteColor(tempBand(celsius))
 }
 
-func setupTable(rows Rows) table.Model {
-	columns := []table.Column{
-		{Title: "Name", Width: 40},
-		{Title: "ID", Width: 10},
-		{Title: "Region", Width: 10},
+func tempBand(celsius string) color {
+	temp, err := parseValue("temperature", celsius)
+	if err != nil {
+		return grey
 	}
 
-	t := table.New(
-		table.WithColumns(columns),
-		table.WithRows(rows),
-		table.WithFocused(false),
-	)
+	switch {
+	case temp >= hotTempThreshold:
+		return pink
+	case temp >= warmTempThreshold:
+		return yellow
+	case temp >= mildTempThreshold:
+		return green
+	default:
+		return blue
+	}
+}
 
-	headerStyle := lipgloss.NewStyle().
-		Padding(0, 1).
-		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color(colorPalette[blue])).
-		BorderBottom(true).
-		Bold(false)
+// format a temperature in the chosen unit, the API always uses celsius
+func formatTemp(celsius string, unit temperatureUnit) string {
+	temp, err := parseValue("temperature", celsius)
+	if err != nil {
+		return missingValue
+	}
 
-	tableStyle = table.DefaultStyles()
-	tableStyle.Header = headerStyle
-	tableStyle.Selected = lipgloss.NewStyle()
+	if unit != fahrenheitUnit {
+		return strconv.Itoa(temp) + string(celsiusUnit)
+	}
 
-	tableStyleFocussed = table.DefaultStyles()
-	tableStyleFocussed.Header = headerStyle
-	tableStyleFocussed.Selected = tableStyleFocussed.Selected.
-		Foreground(lipgloss.Color(colorPalette[black])).
-		Background(lipgloss.Color(colorPalette[green])).
-		Bold(false)
+	return strconv.Itoa(int(math.Round(float64(temp)*9/5+32))) + string(fahrenheitUnit)
+}
 
-	// table is out of focus on load
-	t.SetStyles(tableStyle)
+// a daily period's high from its Day forecast and low from its Night
+// one, either is empty if the period doesn't have it, e.g. today once
+// the Day forecast has passed
+func dayHighLow(period data.Period) (high, low string) {
+	for _, forecast := range period.Forecasts {
+		switch forecast.Time {
+		case "Day":
+			high = forecast.Day.Temperature
+		case "Night":
+			low = forecast.Night.Temperature
+		}
+	}
 
-	return t
+	return high, low
 }
 
-func setupTextInput() textinput.Model {
-	ti := textinput.New()
-	ti.Placeholder = "Search for a placename"
-	ti.Focus()
-	ti.CharLimit = 156
+// e.g. "High 12°C / Low 4°C", with only the half a day has
+func formatHighLow(high, low string, unit temperatureUnit) string {
+	var parts []string
+	if high != "" {
+		parts = append(parts, "High "+formatTemp(high, unit))
+	}
+	if low != "" {
+		parts = append(parts, "Low "+formatTemp(low, unit))
+	}
 
-	return ti
+	return strings.Join(parts, " / ")
 }
 
-func setupList() list.Model {
-	li := list.New(nil, list.NewDefaultDelegate(), 0, 0)
-	li.SetFilteringEnabled(false)
-	li.SetShowTitle(true)
-	li.SetShowStatusBar(false)
-	// remove the default list Quit key bind of 'Esc'
-	li.KeyMap.Quit.Unbind()
-
-	return li
+func renderTemp(celsius string, unit temperatureUnit) string {
+	return lipgloss.NewStyle().Foreground(tempColor(celsius)).Render(formatTemp(celsius, unit))
 }
 
-func initialModel() model {
-	endpoint := "val/wxfcs/all/json/sitelist"
-	url := makeUrl(endpoint)
-	res := data.Fetch(url)
-	if res == nil {
-		log.Fatal("Could not fetch sitelist data.")
+// the feels like temperature, highlighted when it's far enough from the
+// actual temperature to notice
+func renderFeelsLike(fd forecastData, unit temperatureUnit) string {
+	text := formatTemp(fd.FeelsLikeTemp, unit)
+
+	temp, errTemp := fd.TemperatureC()
+	feelsLike, errFeels := fd.FeelsLikeC()
+	if errTemp == nil && errFeels == nil && max(temp-feelsLike, feelsLike-temp) >= feelsLikeThreshold {
+		return lipgloss.NewStyle().Bold(true).Foreground(tempColor(fd.FeelsLikeTemp)).Render(text)
 	}
 
-	rows := extractRows(res)
+	return text
+}
 
-	t := setupTable(rows)
-	ti := setupTextInput()
-	li := setupList()
+// format a wind speed in the chosen unit, from mph as flattenForecast gives it
+func formatWind(mph string, unit windUnit) string {
+	speed, err := parseValue("wind speed", mph)
+	if err != nil {
+		return missingValue
+	}
 
-	return model{
-		textInput:          ti,
-		table:              t,
-		list:               li,
-		forecastResolution: dailyResolution,
+	switch unit {
+	case kphUnit:
+		return strconv.Itoa(int(math.Round(float64(speed)*1.609344))) + string(kphUnit)
+	case knotsUnit:
+		return strconv.Itoa(int(math.Round(float64(speed)*0.868976))) + string(knotsUnit)
+	default:
+		return strconv.Itoa(speed) + string(mphUnit)
 	}
 }
 
-func getSiteData(siteId string, resolution resolution) data.SiteData {
-	endpoint := "val/wxfcs/all/json/" + siteId
-	param := "res=" + string(resolution)
-	url := makeUrl(endpoint, param)
-	res := data.Fetch(url)
-	if res == nil {
-		log.Fatal("Could not fetch site data.")
+// how the air feels from its temperature and humidity
+func comfortLabel(tempC, humidityPct int) string {
+	switch {
+	case humidityPct < dryHumidityThreshold:
+		return "dry"
+	case tempC >= muggyTempThreshold && humidityPct >= muggyHumidityThreshold:
+		return "muggy"
+	default:
+		return "comfortable"
 	}
+}
 
-	var siteData data.SiteData
+// the humidity bar, labelled with how comfortable the air is when the
+// temperature is known too
+func renderHumidity(fd forecastData, unit string, width int) string {
+	text := renderPercent(fd.Humidity, unit, width)
 
-	err := json.Unmarshal(res, &siteData)
-	if err != nil {
-		log.Fatal("Error decoding JSON:", err)
+	temp, errTemp := fd.TemperatureC()
+	humidity, errHumidity := fd.HumidityPct()
+	if errTemp != nil || errHumidity != nil {
+		return text
 	}
 
-	return siteData
+	return text + " (" + comfortLabel(temp, humidity) + ")"
 }
 
-func getForecastListItems(m model) []list.Item {
-	var forecasts []list.Item
+func isWindy(fd forecastData) bool {
+	gust, err := fd.GustSpeedMph()
+	return err == nil && gust >= windyGustThreshold
+}
 
-	for pIndex, period := range m.siteData.Site.Info.Location.Periods {
-		date, err := time.Parse("2006-01-02Z", period.Date)
-		if err != nil {
-			log.Fatal("Failed to parse date", err)
-		}
+// the gust speed, highlighted as a warning when it's windy
+func renderGusts(fd forecastData, unit windUnit) string {
+	if _, err := fd.GustSpeedMph(); err != nil {
+		return "not available"
+	}
 
-		for fIndex, forecast := range period.Forecasts {
-			forecastData := getForecastData(m, forecast)
+	text := "up to " + formatWind(fd.GustSpeed, unit)
+	if isWindy(fd) {
+		return lipgloss.NewStyle().Bold(true).Foreground(paletteColor(pink)).Render("💨 " + text + " - windy!")
+	}
 
-			code := forecastData.WeatherCode
-			desc := data.WeatherCodes[code]
-			desc += " | " + forecastData.Temperature + "°C"
-			desc += " | " + forecastData.WindSpeed + "mph"
+	return text
+}
 
-			var forecastTime = forecastData.Time
+// how far the gusts outrun the sustained wind
+func gustiness(windMph, gustMph int) string {
+	switch gap := gustMph - windMph; {
+	case gap >= squallyGap:
+		return "squally"
+	case gap >= gustyGap:
+		return "gusty"
+	default:
+		return "steady"
+	}
+}
 
-			if m.forecastResolution == threeHourlyResolution {
-				// Time is represented as minutes past midnight here
-				// so convert to 24hr clock representation instead
-				minutes, err := strconv.Atoi(forecastTime)
-				if err != nil {
-					log.Fatal("Couldn't convert time", err)
-				}
-				hours := minutes / 60
-				forecastTime = fmt.Sprintf("%02d:00", hours)
-			}
+// the sustained wind and gusts side by side, e.g. "S 15mph → G 32mph",
+// highlighted as a warning when squally, empty if either is missing
+func renderGustiness(fd forecastData, unit windUnit) string {
+	wind, errWind := fd.WindSpeedMph()
+	gust, errGust := fd.GustSpeedMph()
+	if errWind != nil || errGust != nil {
+		return ""
+	}
 
-			title := date.Format("Mon, 02 Jan 2006") + " (" + forecastTime + ")"
+	label := gustiness(wind, gust)
+	text := "S " + formatWind(fd.WindSpeed, unit) + " → G " + formatWind(fd.GustSpeed, unit) + " (" + label + ")"
+	if label == "squally" {
+		return lipgloss.NewStyle().Bold(true).Foreground(paletteColor(pink)).Render(text)
+	}
 
-			item := forecastItem{title: title, desc: desc, periodIndex: pIndex, forecastIndex: fIndex}
+	return text
+}
 
-			forecasts = append(forecasts, item)
+// the lowest wind speed in mph of each Beaufort force, from calm at 0
+var beaufortScale = []struct {
+	minMph      int
+	description string
+}{
+	{0, "Calm"},
+	{1, "Light air"},
+	{4, "Light breeze"},
+	{8, "Gentle breeze"},
+	{13, "Moderate breeze"},
+	{19, "Fresh breeze"},
+	{25, "Strong breeze"},
+	{32, "Near gale"},
+	{39, "Gale"},
+	{47, "Strong gale"},
+	{55, "Storm"},
+	{64, "Violent storm"},
+	{73, "Hurricane force"},
+}
+
+// the Beaufort force of a wind speed and what it's called
+func beaufort(mph int) (force int, description string) {
+	for i, step := range beaufortScale {
+		if mph >= step.minMph {
+			force, description = i, step.description
 		}
 	}
 
-	return forecasts
+	return force, description
 }
 
-func (m model) Init() tea.Cmd {
-	return textinput.Blink
-}
+// the wind speed with its Beaufort force, highlighted as a warning from
+// a gale upwards, the force is left off if the speed isn't known
+func renderWind(fd forecastData, unit windUnit) string {
+	text := formatWind(fd.WindSpeed, unit)
+	if fd.WindDirection != "" && fd.WindDirection != missingValue {
+		text = windArrow(fd.WindDirection) + " " + fd.WindDirection + " " + text
+	}
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c":
-			return m, tea.Quit
-		}
-	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
+	mph, err := fd.WindSpeedMph()
+	if err != nil {
+		return text
+	}
+
+	force, description := beaufort(mph)
+	text += " (Force " + strconv.Itoa(force) + " – " + description + ")"
 
-		h, v := listStyle.GetFrameSize()
-		m.list.SetSize(msg.Width-h, msg.Height-v)
+	if force >= galeForce {
+		return lipgloss.NewStyle().Bold(true).Foreground(paletteColor(pink)).Render("⚠ " + text)
 	}
 
-	if m.forecastChosen {
-		return updateForecast(msg, m)
-	} else if m.locationChosen {
-		return updateLocation(msg, m)
-	} else {
-		return updateSearch(msg, m)
+	return text
+}
+
+func cycleWindUnit(m model) (model, tea.Cmd) {
+	switch m.windUnit {
+	case kphUnit:
+		m.windUnit = knotsUnit
+	case knotsUnit:
+		m.windUnit = mphUnit
+	default:
+		m.windUnit = kphUnit
 	}
+
+	return relistForecasts(m)
 }
 
-func updateSearch(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
-	var textInputCmd tea.Cmd
-	var tableCmd tea.Cmd
+// switch between 12 and 24 hour times, remembering the choice
+func toggleClock(m model) (model, tea.Cmd) {
+	m.twelveHour = !m.twelveHour
 
-	m.textInput, textInputCmd = m.textInput.Update(msg)
-	m.table, tableCmd = m.table.Update(msg)
+	if err := updateConfig(func(cfg *Config) { cfg.TwelveHour = m.twelveHour }); err != nil {
+		m.notice = "Couldn't save the time format: " + err.Error()
+	}
 
-	cmds = append(cmds, textInputCmd, tableCmd)
+	if m.dayChosen {
+		m.dayTable.SetRows(dayRows(m, m.dayPeriod))
+	}
 
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
-			if m.textInput.Focused() {
-				m.textInput.Blur()
-				m.table.Focus()
-				m.table.SetStyles(tableStyleFocussed)
-			} else if m.table.Focused() {
-				m.locationChosen = true
-				m.locationId = m.table.SelectedRow()[1]
+	return relistForecasts(m)
+}
 
-				m.siteData = getSiteData(m.locationId, m.forecastResolution)
-				forecasts := getForecastListItems(m)
-				cmd := m.list.SetItems(forecasts)
+func toggleTempUnit(m model) (model, tea.Cmd) {
+	if m.tempUnit == fahrenheitUnit {
+		m.tempUnit = celsiusUnit
+	} else {
+		m.tempUnit = fahrenheitUnit
+	}
 
-				m.list.Title = m.siteData.Site.Info.Location.Name + ", " + m.siteData.Site.Info.Location.Country
+	return relistForecasts(m)
+}
 
-				return m, cmd
-			}
-		case "esc":
-			if m.table.Focused() {
-				m.table.Blur()
-				m.table.SetStyles(tableStyle)
-				m.textInput.Focus()
-			}
-		default:
-			input := m.textInput.Value()
+// the second line of a list item with details expanded, leaving out
+// whatever the forecast doesn't have
+func forecastDetails(fd forecastData, tempUnit temperatureUnit, windUnit windUnit) string {
+	var details []string
 
-			if len(input) > 0 {
-				matchedNames := fuzzy.RankFindFold(input, placenames)
-				sort.Sort(matchedNames)
+	if fd.FeelsLikeTemp != "" {
+		details = append(details, "Feels like "+formatTemp(fd.FeelsLikeTemp, tempUnit))
+	}
+	if percent, err := fd.PrecipitationPct(); err == nil {
+		details = append(details, describeRain(percent))
+	}
+	if index, err := fd.UVIndex(); err == nil {
+		details = append(details, "UV "+strconv.Itoa(index))
+	}
+	if _, err := fd.GustSpeedMph(); err == nil {
+		details = append(details, "Gusts "+formatWind(fd.GustSpeed, windUnit))
+	}
 
-				var filteredRows Rows
+	return strings.Join(details, " | ")
+}
 
-				for _, rankedMatch := range matchedNames {
-					index := rankedMatch.OriginalIndex
-					filteredRows = append(filteredRows, rows[index])
-				}
+// switch the list between one line of description for each forecast
+// and two, the taller items fit fewer to a page
+func toggleExpanded(m model) (model, tea.Cmd) {
+	m.expanded = !m.expanded
+	m.list.SetDelegate(newListDelegate(m.expanded))
 
-				m.table.SetRows(filteredRows)
-			} else {
-				m.table.SetRows(rows)
-			}
+	return relistForecasts(m)
+}
 
-		}
+// rebuild the list items in place so their descriptions pick up new settings
+func relistForecasts(m model) (model, tea.Cmd) {
+	if len(m.list.Items()) == 0 {
+		return m, nil
 	}
 
-	return m, tea.Batch(cmds...)
+	cmd := m.list.SetItems(getForecastListItems(m))
+
+	return m, cmd
 }
 
-func updateLocation(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
+// draw a horizontal bar filled in proportion to percent
+func renderBar(percent int, width int) string {
+	percent = max(0, min(100, percent))
+	width = max(0, width)
 
-	var listCmd tea.Cmd
-	m.list, listCmd = m.list.Update(msg)
-	cmds = append(cmds, listCmd)
+	filled := percent * width / 100
 
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "enter":
-			m.forecastChosen = true
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
 
-			item := m.list.SelectedItem().(forecastItem)
-			periodIndex, forecastIndex := item.Position()
-			forecast := m.siteData.Site.Info.Location.Periods[periodIndex].Forecasts[forecastIndex]
+	return lipgloss.NewStyle().Foreground(paletteColor(blue)).Render(bar)
+}
 
-			m.forecastData = getForecastData(m, forecast)
-		case "r":
-			// switch forecast list resolution
-			if m.forecastResolution == dailyResolution {
-				m.forecastResolution = threeHourlyResolution
-			} else {
-				m.forecastResolution = dailyResolution
-			}
+// visibility bands used by forecasts, observations give metres instead
+var visibilityBands = []struct {
+	code  string
+	upTo  int
+	label string
+}{
+	{"VP", 1000, "Very poor (<1km)"},
+	{"PO", 4000, "Poor (1-4km)"},
+	{"MO", 10000, "Moderate (4-10km)"},
+	{"GO", 20000, "Good (10-20km)"},
+	{"VG", 40000, "Very good (20-40km)"},
+	{"EX", math.MaxInt, "Excellent (>40km)"},
+}
 
-			m.siteData = getSiteData(m.locationId, m.forecastResolution)
-			forecasts := getForecastListItems(m)
-			cmd := m.list.SetItems(forecasts)
-			cmds = append(cmds, cmd)
-		case "esc":
-			m.locationChosen = false
+func describeVisibility(v string) string {
+	for _, band := range visibilityBands {
+		if v == band.code {
+			return band.label
 		}
 	}
 
-	return m, tea.Batch(cmds...)
-}
+	metres, err := parseValue("visibility", v)
+	if err != nil || metres < 0 {
+		return "Unknown"
+	}
 
-func updateForecast(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.String() {
-		case "esc":
-			m.forecastChosen = false
+	for _, band := range visibilityBands {
+		if metres < band.upTo {
+			return band.label
 		}
 	}
 
-	return m, nil
+	return "Unknown"
 }
 
-func (m model) View() string {
-	var s string
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
 
-	if m.forecastChosen {
+// scale values between their minimum and maximum onto the block levels,
+// a flat series is drawn along the bottom
+func sparkline(values []int) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	low, high := slices.Min(values), slices.Max(values)
+	top := len(sparkBlocks) - 1
+
+	var line strings.Builder
+	for _, value := range values {
+		level := 0
+		if high > low {
+			level = int(math.Round(float64((value-low)*top) / float64(high-low)))
+		}
+		line.WriteRune(sparkBlocks[level])
+	}
+
+	return line.String()
+}
+
+// the temperatures of the selected item's day as a sparkline coloured by
+// temperature band, only 3hourly forecasts have enough points for a curve
+func temperatureSparkline(m model) string {
+	item, ok := m.list.SelectedItem().(forecastItem)
+	if !ok || m.observing || m.forecastResolution != threeHourlyResolution {
+		return ""
+	}
+
+	periodIndex, _ := item.Position()
+	var temps []int
+	for _, f := range m.siteData.Site.Info.Location.Periods[periodIndex].Forecasts {
+		if temp, err := getForecastData(m, f).TemperatureC(); err == nil {
+			temps = append(temps, temp)
+		}
+	}
+
+	if len(temps) < 2 {
+		return ""
+	}
+
+	blocks := []rune(sparkline(temps))
+	var line strings.Builder
+	for i, temp := range temps {
+		style := lipgloss.NewStyle().Foreground(tempColor(strconv.Itoa(temp)))
+		line.WriteString(style.Render(string(blocks[i])))
+	}
+
+	return line.String() + " " + formatTemp(strconv.Itoa(slices.Min(temps)), m.tempUnit) +
+		"–" + formatTemp(strconv.Itoa(slices.Max(temps)), m.tempUnit)
+}
+
+// render a percentage field as a bar followed by its value,
+// non-numeric values get an empty bar
+func renderPercent(value string, unit string, width int) string {
+	percent, err := parseValue("percentage", value)
+	if err != nil {
+		return renderBar(0, width) + " " + missingValue
+	}
+
+	return renderBar(percent, width) + " " + strconv.Itoa(percent) + unit
+}
+
+// fit bars into the space left over in the current viewport
+func barWidth(viewportWidth int) int {
+	h, _ := listStyle.GetFrameSize()
+	return max(minBarWidth, min(maxBarWidth, viewportWidth-h-barLabelWidth))
+}
+
+// band a UV index using the Met Office scale, the label is empty
+// when there's no UV value, e.g. for nighttime forecasts
+func uvCategory(uv string) (label string, c color) {
+	index, err := parseValue("UV", uv)
+	if err != nil {
+		return "", grey
+	}
+
+	switch {
+	case index >= 11:
+		return "Extreme", purple
+	case index >= 8:
+		return "Very High", purple
+	case index >= 6:
+		return "High", pink
+	case index >= 3:
+		return "Moderate", yellow
+	default:
+		return "Low", green
+	}
+}
+
+// a chance of rain in full, e.g. "60% chance of rain"
+func describeRain(percent int) string {
+	return strconv.Itoa(percent) + "% chance of rain"
+}
+
+// a chance of rain where space is short, e.g. "☂ 60%"
+func compactRain(percent int) string {
+	return "☂ " + strconv.Itoa(percent) + "%"
+}
+
+// whether the chance of rain is building or clearing since the slot
+// before, prev is -1 for the day's first slot or an unknown chance
+func rainTrend(prev, rain int) string {
+	switch {
+	case prev < 0:
+		return "·"
+	case rain > prev:
+		return "↑"
+	case rain < prev:
+		return "↓"
+	default:
+		return "→"
+	}
+}
+
+// how far a wind can wander either way over a day and still be steady,
+// a point of the compass is 22.5°
+const steadyWindDegrees = 45
+
+// whether the wind turns clockwise (veering) or anticlockwise (backing)
+// through a day's directions, following the shorter way round between
+// each pair, empty if there are too few known directions to tell
+func windTrend(directions []string) string {
+	var turned, known float64
+	previous := -1.0
+
+	for _, direction := range directions {
+		degrees, ok := compassDegrees(direction)
+		if !ok {
+			continue
+		}
+
+		if previous >= 0 {
+			turned += math.Mod(degrees-previous+540, 360) - 180
+		}
+		previous = degrees
+		known++
+	}
+
+	switch {
+	case known < 2:
+		return ""
+	case turned > steadyWindDegrees:
+		return "veering"
+	case turned < -steadyWindDegrees:
+		return "backing"
+	default:
+		return "steady"
+	}
+}
+
+func compassDegrees(direction string) (float64, bool) {
+	index := slices.Index(compassPoints, strings.ToUpper(strings.TrimSpace(direction)))
+	if index < 0 {
+		return 0, false
+	}
+
+	return float64(index) * 22.5, true
+}
+
+func windArrow(direction string) string {
+	arrow, ok := windArrows[strings.ToUpper(strings.TrimSpace(direction))]
+	if !ok {
+		return "·"
+	}
+
+	return arrow
+}
+
+func makeUrl(endpoint string, paramList ...string) string {
+	params := ""
+	for _, param := range paramList {
+		params += "&" + param
+	}
+
+	return baseUrl + endpoint + "?key=" + apiKey + params
+}
+
+// build the search table's rows, the sorted placenames and the site
+// coordinates from the sitelist
+func extractRows(body []byte) (Rows, []string, map[string]coordinates, error) {
+	var data struct {
+		Locations locations `json:"locations"`
+	}
+
+	err := json.Unmarshal(body, &data)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error decoding JSON: %w", err)
+	}
+
+	var rows Rows
+	var placenames []string
+	coords := make(map[string]coordinates)
+
+	for _, location := range data.Locations.Location {
+		if c, ok := parseCoordinates(location.Latitude, location.Longitude); ok {
+			coords[location.Id] = c
+		}
+		rows = append(rows, table.Row{location.Name, location.Id, location.Region})
+	}
+
+	if len(rows) == 0 {
+		return nil, nil, nil, errors.New("the sitelist has no sites")
+	}
+
+	rows = disambiguateNames(rows)
+	for _, row := range rows {
+		placenames = append(placenames, row[nameColumn])
+	}
+
+	slices.Sort(placenames)
+	sort.Sort(rowOrder{Rows: rows, column: nameColumn})
+
+	return rows, placenames, coords, nil
+}
+
+// the suffix telling a site apart from others with the same name, with
+// its id too for those in the same region
+func nameSuffix(row table.Row, withId bool) string {
+	if withId {
+		return " (" + regionName(row[regionColumn]) + ", " + row[idColumn] + ")"
+	}
+
+	return " (" + regionName(row[regionColumn]) + ")"
+}
+
+// a site's name as the sitelist gives it, without any suffix
+func placename(row table.Row) string {
+	for _, withId := range []bool{true, false} {
+		if name, ok := strings.CutSuffix(row[nameColumn], nameSuffix(row, withId)); ok {
+			return name
+		}
+	}
+
+	return row[nameColumn]
+}
+
+// append the region to the names shared by more than one site, such as
+// the Newports, leaving the names only one site has alone. Sites with
+// the same name in the same region get their id as well. Names that
+// were told apart before are grouped by the name they started as
+func disambiguateNames(rows Rows) Rows {
+	// the ids of the sites with each name, and each name and region, a
+	// site can be listed twice
+	sites := make(map[string]map[string]bool)
+	addSite := func(name, id string) {
+		if sites[name] == nil {
+			sites[name] = make(map[string]bool)
+		}
+		sites[name][id] = true
+	}
+	for _, row := range rows {
+		name := placename(row)
+		addSite(name, row[idColumn])
+		addSite(name+nameSuffix(row, false), row[idColumn])
+	}
+
+	for i, row := range rows {
+		name := placename(row)
+		if len(sites[name]) > 1 {
+			withId := len(sites[name+nameSuffix(row, false)]) > 1
+			name += nameSuffix(row, withId)
+		}
+
+		if name != row[nameColumn] {
+			rows[i] = slices.Clone(row)
+			rows[i][nameColumn] = name
+		}
+	}
+
+	return rows
+}
+
+// narrow terminals get a compact search table without the region and
+// data columns or border
+func isCompact(width int) bool {
+	return width > 0 && width < compactWidth
+}
+
+// the search table's columns for a terminal width, a width of 0 means
+// the size isn't known yet, the sorted column is marked with an arrow
+// showing its direction
+func tableColumns(sortColumn int, descending bool, width int) []table.Column {
+	columns := []table.Column{
+		{Title: "Name", Width: minNameWidth},
+		{Title: "ID", Width: 10},
+		{Title: "Region", Width: 10},
+		{Title: "Data", Width: 8},
+	}
+
+	if width >= compactWidth {
+		// what's left inside the border after the other columns and
+		// each cell's padding
+		nameSpace := func() int {
+			available := width - frameWidth(borderStyle) - 2*len(columns)
+			for _, column := range columns[idColumn:] {
+				available -= column.Width
+			}
+			return available
+		}
+
+		// the data column goes before the name is squeezed
+		if nameSpace() < minNameWidth {
+			columns = columns[:dataColumn]
+		}
+		columns[nameColumn].Width = max(minNameWidth, min(nameSpace(), maxNameWidth))
+	}
+
+	if isCompact(width) {
+		columns = columns[:regionColumn]
+
+		// share what's left after each cell's padding between name and id
+		available := width - 2*len(columns)
+		columns[idColumn].Width = max(available/5, minIdWidth)
+		columns[nameColumn].Width = max(available-columns[idColumn].Width, 1)
+	}
+
+	// the sort column may be hidden in compact mode
+	if sortColumn < len(columns) {
+		arrow := " ▲"
+		if descending {
+			arrow = " ▼"
+		}
+		columns[sortColumn].Title += arrow
+	}
+
+	return columns
+}
+
+// trim rows to the columns shown at a terminal width, the table
+// can't render more values than it has columns
+func fitRows(rows Rows, width int) Rows {
+	columns := len(tableColumns(nameColumn, false, width))
+
+	fitted := make(Rows, len(rows))
+	for i, row := range rows {
+		fitted[i] = row[:min(len(row), columns)]
+	}
+
+	return fitted
+}
+
+// show rows in the search table, keeping the full rows so they can be
+// refitted if the terminal is resized
+func showRows(m model, rows Rows) model {
+	m.tableRows = rows
+	m.table.SetRows(fitRows(rows, m.width))
+
+	return m
+}
+
+// recompute the search table's columns for the current width and sort
+func layoutTable(m model) model {
+	// clear the rows first so they never outnumber the columns
+	m.table.SetRows(nil)
+	m.table.SetColumns(tableColumns(m.sortColumn, m.sortDescending, m.width))
+	m.table.SetRows(fitRows(m.tableRows, m.width))
+	if m.height > 0 {
+		m.table.SetHeight(max(1, m.height-searchChrome))
+	}
+
+	return m
+}
+
+// the width a style's border and padding add, lipgloss only counts
+// border sides that were set explicitly
+func frameWidth(style lipgloss.Style) int {
+	return lipgloss.Width(style.Render(""))
+}
+
+func setupTable(rows Rows, width int) table.Model {
+	t := table.New(
+		table.WithColumns(tableColumns(nameColumn, false, width)),
+		table.WithRows(fitRows(rows, width)),
+		table.WithFocused(false),
+	)
+
+	// table is out of focus on load
+	t.SetStyles(tableStyle)
+
+	return t
+}
+
+// the search table with alternating row backgrounds, the table can't
+// style rows itself so they're found on screen like clicks are
+func stripeTable(m model) string {
+	view := m.table.View()
+
+	selected := m.table.SelectedRow()
+	if selected == nil {
+		return view
+	}
+
+	lines := strings.Split(view, "\n")
+	plain := screenLines(view)
+	columns := tableColumns(m.sortColumn, m.sortDescending, m.width)
+	selectedLine := findLine(plain, func(line string) bool {
+		return rowOnLine(selected, columns, line)
+	})
+	if selectedLine < 0 {
+		return view
+	}
+
+	// the characters of each name matched by the search are picked out
+	_, query := parseSearchInput(m.textInput.Value())
+	query = strings.TrimSpace(query)
+
+	rows := m.table.Rows()
+	for i := range lines {
+		row := m.table.Cursor() + i - selectedLine
+		if row < 0 || row >= len(rows) || !rowOnLine(rows[row], columns, plain[i]) {
+			continue
+		}
+
+		var positions []int
+		if query != "" {
+			positions = matchedPositions(query, rows[row][nameColumn])
+		}
+
+		// the highlight of a focused selection wins over its stripe
+		if i == selectedLine && m.table.Focused() {
+			if len(positions) > 0 {
+				base := tableStyleFocussed.Selected
+				lines[i] = highlightRow(plain[i], rows[row][nameColumn], columns[nameColumn].Width, positions, base, base.Copy().Bold(true).Underline(true))
+			}
+			continue
+		}
+
+		shade := stripe
+		if row%2 == 1 {
+			shade = stripeAlt
+		}
+		base := lipgloss.NewStyle().Background(paletteColor(shade))
+		match := base.Copy().Bold(true).Foreground(paletteColor(pink))
+		if noColor {
+			match = match.Underline(true)
+		}
+		lines[i] = highlightRow(plain[i], rows[row][nameColumn], columns[nameColumn].Width, positions, base, match)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// build the table styles from the active theme's palette
+func setupTableStyles() {
+	headerStyle := lipgloss.NewStyle().
+		Padding(0, 1).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(paletteColor(blue)).
+		BorderBottom(true).
+		Bold(false)
+
+	tableStyle = table.DefaultStyles()
+	tableStyle.Header = headerStyle
+	tableStyle.Selected = lipgloss.NewStyle()
+
+	tableStyleFocussed = table.DefaultStyles()
+	tableStyleFocussed.Header = headerStyle
+	tableStyleFocussed.Selected = tableStyleFocussed.Selected.
+		Foreground(paletteColor(black)).
+		Background(paletteColor(green)).
+		Bold(false)
+
+	// without colour the selected row is underlined instead
+	if noColor {
+		tableStyleFocussed.Selected = tableStyleFocussed.Selected.Underline(true)
+	}
+}
+
+func setupTextInput() textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = "Search for a placename (region:<region> to narrow)"
+	ti.Focus()
+	ti.CharLimit = 156
+
+	return ti
+}
+
+func setupList() list.Model {
+	li := list.New(nil, newListDelegate(false), 0, 0)
+	li.Styles.Title = listTitleStyle()
+	li.SetFilteringEnabled(false)
+	li.SetShowTitle(true)
+	// the footer shows the position, e.g. "3 of 16", in its place
+	li.SetShowStatusBar(false)
+	// remove the default list Quit key bind of 'Esc', esc goes back to
+	// the search and q is handled for every view in Update
+	li.KeyMap.Quit.Unbind()
+	backKeys := func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back to search")),
+			key.NewBinding(key.WithKeys("q"), key.WithHelp("q", "quit")),
+		}
+	}
+	li.AdditionalShortHelpKeys = backKeys
+	li.AdditionalFullHelpKeys = func() []key.Binding {
+		return append(backKeys(), dateFilterHelp()...)
+	}
+	// jump to either end of long 3hourly lists
+	li.KeyMap.GoToStart = key.NewBinding(
+		key.WithKeys("g", "home"),
+		key.WithHelp("g/home", "go to start"),
+	)
+	li.KeyMap.GoToEnd = key.NewBinding(
+		key.WithKeys("G", "end"),
+		key.WithHelp("G/end", "go to end"),
+	)
+
+	return li
+}
+
+// build the model from the config, applying its theme before any
+// components are styled
+// the sitelist is fetched once the program has started, see Init
+func initialModel(cfg Config) model {
+	m, err := applyConfig(model{fetcher: data.DefaultSource}, cfg)
+	if err != nil {
+		m.err = fmt.Errorf("invalid settings: %w", err)
+	}
+	applyTheme(themes[m.themeIndex])
+
+	m.table = setupTable(nil, 0)
+	m.textInput = setupTextInput()
+	m.list = setupList()
+	m.spinner = spinner.New(spinner.WithSpinner(spinner.Dot))
+	m.loadingSites = m.err == nil
+	m.startLocation = cfg.Location
+
+	return m
+}
+
+type sitelistLoadedMsg struct {
+	rows       Rows
+	placenames []string
+	coords     map[string]coordinates
+	// nil if the observation sitelist couldn't be loaded
+	observationSites map[string]bool
+	err              error
+}
+
+// how much of the sitelist has downloaded, sent as it arrives
+type sitelistProgressMsg struct {
+	read  int64
+	total int64
+	// where the rest of the download's messages come from
+	updates <-chan tea.Msg
+}
+
+// load the sitelist in the background, the first message is its
+// progress when the download reports any, the last is always a
+// sitelistLoadedMsg
+func loadSitelist(m model) tea.Cmd {
+	return func() tea.Msg {
+		updates := make(chan tea.Msg, 1)
+
+		go func() {
+			ctx := data.WithProgress(context.Background(), func(read, total int64) {
+				// the splash only needs the latest, so don't hold up the download
+				select {
+				case updates <- sitelistProgressMsg{read: read, total: total, updates: updates}:
+				default:
+				}
+			})
+
+			updates <- m.fetchSitelists(ctx)
+		}()
+
+		return <-updates
+	}
+}
+
+func nextSitelistUpdate(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-updates
+	}
+}
+
+// the sitelists of forecast and observation sites merged, only the
+// larger forecast sitelist reports its progress to ctx
+func (m model) fetchSitelists(ctx context.Context) sitelistLoadedMsg {
+	var msg sitelistLoadedMsg
+
+	// a sitelist that arrives empty or garbled gets one more try
+	for attempt := 0; attempt < sitelistAttempts; attempt++ {
+		msg.rows, msg.placenames, msg.coords, msg.err = m.getSitelist(ctx)
+		if msg.err == nil {
+			break
+		}
+
+		slog.Warn("sitelist attempt failed", "attempt", attempt+1, "err", msg.err)
+	}
+
+	if msg.err != nil {
+		return msg
+	}
+
+	// observations are a bonus, the search works without their sites
+	obsRows, _, obsCoords, err := m.getObservationSitelist(context.Background())
+	if err != nil {
+		slog.Warn("loading observation sitelist", "err", err)
+	} else {
+		msg.observationSites = make(map[string]bool)
+		for _, row := range obsRows {
+			msg.observationSites[row[idColumn]] = true
+		}
+		for id, c := range obsCoords {
+			if _, ok := msg.coords[id]; !ok {
+				msg.coords[id] = c
+			}
+		}
+	}
+
+	msg.rows = mergeSitelists(msg.rows, obsRows)
+	msg.placenames = nil
+	for _, row := range msg.rows {
+		msg.placenames = append(msg.placenames, row[nameColumn])
+	}
+	slices.Sort(msg.placenames)
+
+	return msg
+}
+
+// the chosen site's name and country, with its region too if other
+// sites share the name
+func locationTitle(m model) string {
+	location := m.siteData.Site.Info.Location
+	name := location.Name
+
+	for _, row := range m.allRows {
+		if row[idColumn] == m.locationId {
+			name += strings.TrimPrefix(row[nameColumn], placename(row))
+			break
+		}
+	}
+
+	return name + ", " + location.Country
+}
+
+// one row for each site in either sitelist, marked with whether it has
+// forecasts, observations or both
+func mergeSitelists(forecast, obs Rows) Rows {
+	observed := make(map[string]bool)
+	for _, row := range obs {
+		observed[row[idColumn]] = true
+	}
+
+	var merged Rows
+	seen := make(map[string]bool)
+
+	for _, row := range forecast {
+		id := row[idColumn]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		marker := forecastsOnly
+		if observed[id] {
+			marker = withObservations
+		}
+		merged = append(merged, append(slices.Clone(row[:dataColumn]), marker))
+	}
+
+	for _, row := range obs {
+		id := row[idColumn]
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		merged = append(merged, append(slices.Clone(row[:dataColumn]), observationsOnly))
+	}
+
+	// an observation site can share a name with a forecast site
+	merged = disambiguateNames(merged)
+	sort.Sort(rowOrder{Rows: merged, column: nameColumn})
+
+	return merged
+}
+
+// whether a site in the sitelist has forecasts and observations, sites
+// not in it are assumed to have forecasts
+func siteDataKinds(rows Rows, siteId string) (forecasts bool, observations bool) {
+	for _, row := range rows {
+		if row[idColumn] == siteId && len(row) > dataColumn {
+			return row[dataColumn] != observationsOnly, row[dataColumn] != forecastsOnly
+		}
+	}
+
+	return true, false
+}
+
+func handleSitelist(m model, msg sitelistLoadedMsg) (model, tea.Cmd) {
+	m.loadingSites = false
+
+	// there's nothing to search without the sitelist, so explain why
+	if msg.err != nil {
+		slog.Error("loading sitelist", "err", msg.err)
+		m.err = fmt.Errorf("could not load the list of sites: %w", msg.err)
+		return m, nil
+	}
+
+	m.allRows, m.placenames, m.siteCoords = msg.rows, msg.placenames, msg.coords
+	m.observationSites = msg.observationSites
+	m.tableRows = m.allRows
+	m = layoutTable(m)
+
+	// a location may have been chosen up front, but it might since
+	// have been dropped from the sitelist
+	if m.startLocation != "" {
+		if !slices.ContainsFunc(m.allRows, func(row table.Row) bool { return row[idColumn] == m.startLocation }) {
+			m.notice = "Location " + m.startLocation + " is no longer available, search for another"
+			return m, nil
+		}
+
+		m.textInput.Blur()
+		return chooseLocation(m, m.startLocation)
+	}
+
+	if m.locateNearby {
+		return m, locateByIP(m)
+	}
+
+	if len(m.favourites) > 0 {
+		return openDashboard(m)
+	}
+
+	return m, nil
+}
+
+func (m model) getSitelist(ctx context.Context) (Rows, []string, map[string]coordinates, error) {
+	url := makeUrl("val/wxfcs/all/json/sitelist")
+
+	res, err := m.fetcher.Get(ctx, url)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return extractRows(res)
+}
+
+func (m model) getSiteData(ctx context.Context, siteId string, resolution resolution) (data.SiteData, error) {
+	endpoint := "val/wxfcs/all/json/" + siteId
+	param := "res=" + string(resolution)
+	url := makeUrl(endpoint, param)
+
+	var siteData data.SiteData
+
+	// the capabilities only help, so the forecast is still tried without
+	if timesteps, err := m.getCapabilities(ctx, resolution); err != nil {
+		slog.Warn("checking capabilities", "resolution", resolution, "err", err)
+	} else if len(timesteps) == 0 {
+		return siteData, errNoTimesteps
+	}
+
+	res, err := m.fetcher.Get(ctx, url)
+	if err != nil {
+		return siteData, fmt.Errorf("could not fetch site data: %w", err)
+	}
+
+	err = json.Unmarshal(res, &siteData)
+	if err != nil {
+		return siteData, fmt.Errorf("error decoding JSON: %w", err)
+	}
+
+	return siteData, nil
+}
+
+func (m model) getObservationData(ctx context.Context, siteId string) (data.SiteData, error) {
+	endpoint := "val/wxobs/all/json/" + siteId
+	param := "res=" + string(hourlyResolution)
+	url := makeUrl(endpoint, param)
+
+	var siteData data.SiteData
+
+	res, err := m.fetcher.Get(ctx, url)
+	if err != nil {
+		return siteData, fmt.Errorf("could not fetch observation data: %w", err)
+	}
+
+	err = json.Unmarshal(res, &siteData)
+	if err != nil {
+		return siteData, fmt.Errorf("error decoding JSON: %w", err)
+	}
+
+	return siteData, nil
+}
+
+// observations come from a different, smaller set of sites than forecasts
+func (m model) getObservationSitelist(ctx context.Context) (Rows, []string, map[string]coordinates, error) {
+	url := makeUrl("val/wxobs/all/json/sitelist")
+
+	res, err := m.fetcher.Get(ctx, url)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("could not fetch observation sitelist: %w", err)
+	}
+
+	return extractRows(res)
+}
+
+func (m model) getObservationSites(ctx context.Context) (map[string]bool, error) {
+	rows, _, _, err := m.getObservationSitelist(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sites := make(map[string]bool)
+	for _, row := range rows {
+		sites[row[idColumn]] = true
+	}
+
+	return sites, nil
+}
+
+// fetch whichever kind of data the model is set up to view
+func (m model) getViewedData(ctx context.Context) (data.SiteData, error) {
+	if m.observing {
+		return m.getObservationData(ctx, m.locationId)
+	}
+
+	return m.getSiteData(ctx, m.locationId, m.forecastResolution)
+}
+
+// fetch site data in the background, the request is abandoned
+// as soon as the user leaves the location
+func fetchSiteData(m model, reason fetchReason) tea.Cmd {
+	id, ctx := m.sessionId, m.ctx
+
+	return func() tea.Msg {
+		siteData, err := m.getViewedData(ctx)
+
+		return siteDataMsg{
+			id:         id,
+			reason:     reason,
+			siteData:   siteData,
+			resolution: m.forecastResolution,
+			observing:  m.observing,
+			err:        err,
+		}
+	}
+}
+
+func fetchObservationSites(m model) tea.Cmd {
+	id, ctx := m.sessionId, m.ctx
+
+	return func() tea.Msg {
+		sites, err := m.getObservationSites(ctx)
+		return observationSitesMsg{id: id, sites: sites, err: err}
+	}
+}
+
+// open the location picked in the search table and start loading it
+func chooseLocation(m model, locationId string) (model, tea.Cmd) {
+	m.locationChosen = true
+	m.locationId = locationId
+	// some sites only report observations
+	forecasts, _ := siteDataKinds(m.allRows, locationId)
+	m.observing = !forecasts
+	m.loading = true
+	m.notice = ""
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m = layoutList(m)
+
+	// a second location is fetched at the pinned one's resolution so
+	// their slots line up
+	if m.compareId != "" && m.compareId != locationId {
+		m.forecastResolution = m.compareResolution
+	}
+
+	// the forecast may already have been fetched while it was highlighted
+	if hasPrefetched(m, locationId) {
+		data.DefaultClient.Metrics.CacheHit()
+		msg := siteDataMsg{id: m.sessionId, reason: fetchSelect, siteData: m.prefetched.siteData, resolution: m.prefetched.resolution}
+		m, cmd := handleSiteData(m, msg)
+
+		return m, tea.Batch(cmd, fetchWarnings(m))
+	}
+
+	data.DefaultClient.Metrics.CacheMiss()
+
+	return m, tea.Batch(fetchSiteData(m, fetchSelect), fetchWarnings(m))
+}
+
+// go back to the search, cancelling anything still in flight
+func leaveLocation(m model) model {
+	if m.cancel != nil {
+		m.cancel()
+	}
+
+	m.sessionId++
+	m.ctx, m.cancel = nil, nil
+	m.locationChosen = false
+	m.forecastChosen = false
+	m.summaryChosen = false
+	m.regionalChosen = false
+	m.dayChosen = false
+	m.comparing = false
+	m.compareOffset = 0
+	m.carouselDate = time.Time{}
+	m.dateFilter = noFilter
+	m.observing = false
+	m.loading = false
+	m.notice = ""
+	m.err = nil
+	m.lastUpdated = time.Time{}
+	m.siteData = data.SiteData{}
+	m.list.SetItems(nil)
+
+	return m
+}
+
+// leave the location and put the search back how it was when the
+// location was chosen, with the table focused on the chosen row
+func returnToSearch(m model) model {
+	m = leaveLocation(m)
+
+	m.textInput.Blur()
+	m.table.Focus()
+	m.table.SetStyles(tableStyleFocussed)
+
+	return m
+}
+
+func handleSiteData(m model, msg siteDataMsg) (model, tea.Cmd) {
+	m.loading = false
+	m.notice = ""
+
+	if msg.err != nil {
+		slog.Error("fetching site data", "location", m.locationId, "observing", msg.observing, "err", msg.err)
+
+		switch msg.reason {
+		case fetchSelect:
+			m.err = msg.err
+			return m, nil
+		case fetchRefresh:
+			// a failed refresh keeps showing the data we already have
+			return scheduleRefresh(m)
+		default:
+			m.notice = describeError(msg.err)
+			return m, nil
+		}
+	}
+
+	index, itemCount := m.list.Index(), len(m.list.Items())
+
+	m.siteData = msg.siteData
+	m.forecastResolution = msg.resolution
+	m.observing = msg.observing
+	cmd := m.list.SetItems(getForecastListItems(m))
+
+	switch msg.reason {
+	case fetchSelect:
+		m.list.Title = locationTitle(m)
+		m.list.Select(nowIndex(m.list.Items()))
+
+		// go straight to the comparison once a second location is picked
+		m = startComparing(m)
+
+		m, refresh := scheduleRefresh(m)
+
+		return m, tea.Batch(cmd, refresh)
+	case fetchRefresh:
+		// keep the current selection where the list still allows it
+		m.list.Select(min(index, max(0, len(m.list.Items())-1)))
+		m = rereadForecast(m)
+		m.lastUpdated = clock()
+
+		m, refresh := scheduleRefresh(m)
+
+		return m, tea.Batch(cmd, refresh)
+	case fetchRefreshNow:
+		// the selection only means the same thing if the list is unchanged
+		if len(m.list.Items()) != itemCount {
+			index = 0
+		}
+		m.list.Select(index)
+		m = rereadForecast(m)
+		m.lastUpdated = clock()
+		m.notice = "Refreshed"
+
+		return m, cmd
+	default:
+		m.list.Select(nowIndex(m.list.Items()))
+
+		return m, cmd
+	}
+}
+
+// where the list should start, on the slot under way if it has one
+func nowIndex(items []list.Item) int {
+	for i, item := range items {
+		if item.(forecastItem).now {
+			return i
+		}
+	}
+
+	return 0
+}
+
+// refresh the detail view from the list's selected item
+func rereadForecast(m model) model {
+	if !m.forecastChosen {
+		return m
+	}
+
+	item, ok := m.list.SelectedItem().(forecastItem)
+	if !ok {
+		m.forecastChosen = false
+		return m
+	}
+
+	periodIndex, forecastIndex := item.Position()
+	forecast := m.siteData.Site.Info.Location.Periods[periodIndex].Forecasts[forecastIndex]
+	m.forecastData = getForecastData(m, forecast)
+
+	return m
+}
+
+// re-fetch the data being viewed straight away
+func refreshNow(m model) (model, tea.Cmd) {
+	if m.loading {
+		return m, nil
+	}
+
+	m.notice = "Refreshing…"
+	if throttled(m) {
+		m.notice = "Refreshing… rate limited, waiting"
+	}
+
+	return m, tea.Batch(fetchSiteData(m, fetchRefreshNow), fetchWarnings(m))
+}
+
+// schedule the next auto-refresh, if enabled, replacing any already
+// scheduled so there's only ever one on the way
+func scheduleRefresh(m model) (model, tea.Cmd) {
+	m.refreshId++
+	if m.refreshInterval <= 0 {
+		return m, nil
+	}
+
+	id, refresh := m.sessionId, m.refreshId
+
+	return m, tea.Tick(m.refreshInterval, func(time.Time) tea.Msg {
+		return refreshTickMsg{id: id, refresh: refresh}
+	})
+}
+
+func getForecastListItems(m model) []list.Item {
+	var forecasts []list.Item
+
+	// observations are all in the past, so only forecasts have a now
+	nowPeriod, nowForecast, _, upcoming := slotIndexAt(m.siteData, clock())
+	upcoming = upcoming && !m.observing && m.forecastResolution == threeHourlyResolution
+
+	for pIndex, period := range m.siteData.Site.Info.Location.Periods {
+		// each period covers a day
+		if m.maxDays > 0 && pIndex >= m.maxDays {
+			break
+		}
+
+		// a period without a usable date can't be placed, so leave it out
+		date, err := parsePeriodDate(period.Date)
+		if err != nil || !m.dateFilter.includes(date, clock()) {
+			continue
+		}
+
+		// the chance of rain in the day's slot before, -1 if unknown
+		prevRain := -1
+
+		for fIndex, forecast := range period.Forecasts {
+			forecastData := getForecastData(m, forecast)
+
+			code := slotWeatherCode(m, date, forecastData)
+			desc := describeCode(code, m.language)
+			desc += " | " + renderTemp(forecastData.Temperature, m.tempUnit)
+			desc += " | " + windArrow(forecastData.WindDirection) + " " + formatWind(forecastData.WindSpeed, m.windUnit)
+			if isWindy(forecastData) {
+				desc += " 💨"
+			}
+			if !m.observing && m.forecastResolution == threeHourlyResolution {
+				rain, err := forecastData.PrecipitationPct()
+				if err != nil {
+					rain = -1
+				} else {
+					desc += " | " + compactRain(rain) + " " + rainTrend(prevRain, rain)
+				}
+				prevRain = rain
+			}
+			if m.expanded {
+				if details := forecastDetails(forecastData, m.tempUnit, m.windUnit); details != "" {
+					desc += "\n" + details
+				}
+			}
+
+			title := date.Format("Mon, 02 Jan 2006") + " (" + forecastData.Time + ")"
+
+			// Time is represented as minutes past midnight here
+			// so convert to the local 24hr clock instead
+			if m.observing || m.forecastResolution == threeHourlyResolution {
+				if t, ok := localSlotTime(date, forecastData.Time); ok {
+					title = t.Format("Mon, 02 Jan 2006") + " (" + clockTime(t, m.twelveHour) + ")"
+				}
+			}
+
+			// the day's first item sums up its Day and Night forecasts
+			if fIndex == 0 && !m.observing && m.forecastResolution == dailyResolution {
+				high, low := dayHighLow(period)
+				if highLow := formatHighLow(high, low, m.tempUnit); highLow != "" {
+					title += " · " + highLow
+				}
+			}
+
+			item := forecastItem{title: title, desc: desc, periodIndex: pIndex, forecastIndex: fIndex}
+			if upcoming && pIndex == nowPeriod && fIndex == nowForecast {
+				item.now = true
+				item.title += " · now"
+			}
+
+			forecasts = append(forecasts, item)
+		}
+	}
+
+	return forecasts
+}
+
+func (m model) Init() tea.Cmd {
+	if m.loadingSites {
+		return tea.Batch(textinput.Blink, m.spinner.Tick, loadSitelist(m))
+	}
+
+	return textinput.Blink
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, quit(m)
+		case "q":
+			if !typing(m) {
+				return m, quit(m)
+			}
+		case "t":
+			// leave the key free for typing into the search input, the
+			// settings change it themselves so they show the new value
+			if !m.textInput.Focused() && !m.settingsChosen {
+				return cycleTheme(m), nil
+			}
+		case "c":
+			if !m.textInput.Focused() && !m.settingsChosen {
+				return toggleTempUnit(m)
+			}
+		case "m":
+			if !m.textInput.Focused() && !m.settingsChosen {
+				return cycleWindUnit(m)
+			}
+		case "H":
+			if !m.textInput.Focused() {
+				return toggleClock(m)
+			}
+		case "s":
+			// the search sorts its results with s, so it's S there
+			if !typing(m) && (m.locationChosen || m.dashboardChosen) && !m.settingsChosen {
+				return openSettings(m), nil
+			}
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+		m = layoutList(m)
+
+		// a hidden region column can't stay the sort column
+		if m.sortColumn >= len(tableColumns(nameColumn, false, m.width)) {
+			m.sortColumn = nameColumn
+			m = showRows(m, sortRows(m.tableRows, m.sortColumn, m.sortDescending))
+		}
+		m = layoutTable(m)
+		m = layoutRegional(m)
+		if m.settingsChosen {
+			m = layoutSettings(m)
+		}
+
+		_, v := listStyle.GetFrameSize()
+		m.dayTable.SetHeight(max(1, msg.Height-v-dayChrome))
+	case refreshTickMsg:
+		// ignore ticks scheduled before the user went back to search, or
+		// since replaced by another
+		if msg.id != m.sessionId || msg.refresh != m.refreshId || !m.locationChosen {
+			return m, nil
+		}
+
+		return m, tea.Batch(fetchSiteData(m, fetchRefresh), fetchWarnings(m))
+	case seenMsg:
+		// the user may have moved on to another forecast since
+		if msg.key == m.changesKey {
+			m.seenBefore = msg.before
+		}
+
+		return m, nil
+	case warningsMsg:
+		// a failed fetch keeps showing any warnings we already have
+		if msg.err != nil {
+			slog.Warn("fetching warnings", "region", msg.region, "err", msg.err)
+			return m, nil
+		}
+
+		m.warnings, m.warningsRegion, m.warningsFetched = msg.warnings, msg.region, time.Now()
+
+		return layoutList(m), nil
+	case siteDataMsg:
+		if msg.id != m.sessionId {
+			return m, nil
+		}
+
+		return handleSiteData(m, msg)
+	case observationSitesMsg:
+		if msg.id != m.sessionId {
+			return m, nil
+		}
+
+		if msg.err != nil {
+			slog.Error("fetching observation sites", "err", msg.err)
+			m.notice = "Observations are unavailable right now"
+			return m, nil
+		}
+
+		m.observationSites = msg.sites
+
+		return toggleObservations(m)
+	case sitelistProgressMsg:
+		m.sitelistRead, m.sitelistTotal = msg.read, msg.total
+		return m, nextSitelistUpdate(msg.updates)
+	case sitelistLoadedMsg:
+		return handleSitelist(m, msg)
+	case spinner.TickMsg:
+		if !m.loadingSites {
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+
+		return m, cmd
+	case regionalTextMsg:
+		if msg.id != m.sessionId {
+			return m, nil
+		}
+
+		if msg.err != nil {
+			slog.Error("fetching regional forecast", "location", m.locationId, "err", msg.err)
+			m.notice = "The regional forecast is unavailable right now"
+			return m, nil
+		}
+
+		m.notice = ""
+
+		return openRegional(m, msg.text), nil
+	case cardMsg:
+		return handleCard(m, msg), nil
+	case prefetchTickMsg:
+		return m, handlePrefetchTick(m, msg)
+	case prefetchedMsg:
+		return handlePrefetched(m, msg), nil
+	case copiedMsg:
+		// headless and SSH sessions usually have no clipboard to copy to
+		if msg.err != nil {
+			m.notice = "Couldn't copy, no clipboard is available here"
+			return m, nil
+		}
+
+		m.notice = "Copied!"
+
+		return m, nil
+	}
+
+	if m.enteringKey {
+		return updateKeyEntry(msg, m)
+	} else if m.loadingSites {
+		return m, nil
+	} else if m.err != nil {
+		return updateError(msg, m)
+	} else if m.settingsChosen {
+		return updateSettings(msg, m)
+	} else if m.dashboardChosen {
+		return updateDashboard(msg, m)
+	} else if m.forecastChosen {
+		return updateForecast(msg, m)
+	} else if m.summaryChosen {
+		return updateSummary(msg, m)
+	} else if m.regionalChosen {
+		return updateRegional(msg, m)
+	} else if m.comparing {
+		return updateCompare(msg, m)
+	} else if m.dayChosen {
+		return updateDay(msg, m)
+	} else if m.locationChosen && m.carousel {
+		return updateCarousel(msg, m)
+	} else if m.locationChosen {
+		return updateLocation(msg, m)
+	} else {
+		return updateSearch(msg, m)
+	}
+}
+
+func updateSearch(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	var textInputCmd tea.Cmd
+	var tableCmd tea.Cmd
+
+	m.textInput, textInputCmd = m.textInput.Update(msg)
+	m.table, tableCmd = m.table.Update(msg)
+
+	cmds = append(cmds, textInputCmd, tableCmd)
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		// the table's shortcuts are only taken once it's focused, until
+		// then they're typed into the search like any other letter
+		tableFocused := m.table.Focused()
+
+		switch key := msg.String(); {
+		case key == "enter":
+			if m.textInput.Focused() {
+				input := strings.TrimSpace(m.textInput.Value())
+				if strings.HasPrefix(strings.ToLower(input), idPrefix) {
+					m, cmd := chooseSiteId(m, strings.TrimSpace(input[len(idPrefix):]))
+					cmds = append(cmds, cmd)
+
+					return m, tea.Batch(cmds...)
+				}
+
+				if looksLikePostcode(input) {
+					if !isPostcode(input) {
+						m.notice = "That doesn't look like a full UK postcode"
+						break
+					}
+
+					m.notice = "Finding sites near " + strings.ToUpper(input) + "…"
+					cmds = append(cmds, lookupPostcode(m, input))
+					break
+				}
+
+				// stay in the input to change a search that found nothing
+				if len(m.table.Rows()) == 0 {
+					m.notice = noMatchesNotice
+					break
+				}
+
+				m = focusTable(m)
+			} else if m.table.Focused() {
+				m, cmd := chooseSelectedRow(m)
+				cmds = append(cmds, cmd)
+
+				return m, tea.Batch(cmds...)
+			}
+		case key == "esc":
+			if m.table.Focused() {
+				m.table.Blur()
+				m.table.SetStyles(tableStyle)
+				m.textInput.Focus()
+			} else if m.textInput.Focused() {
+				m = clearSearch(m)
+			}
+		case key == "ctrl+u":
+			// the input only deletes up to its cursor on its own
+			if m.textInput.Focused() {
+				m = clearSearch(m)
+			}
+		case key == "s" && tableFocused:
+			m.sortColumn = (m.sortColumn + 1) % len(tableColumns(nameColumn, false, m.width))
+			m = layoutTable(m)
+			m = filterTable(m)
+		case key == "o" && tableFocused:
+			m.sortDescending = !m.sortDescending
+			m = layoutTable(m)
+			m = filterTable(m)
+		case key == "h" && tableFocused:
+			m = setHome(m)
+		case key == "S" && tableFocused:
+			return openSettings(m), tea.Batch(cmds...)
+		// f and d already page the table
+		case key == "F" && tableFocused:
+			m = toggleFavourite(m)
+		case key == "D" && tableFocused:
+			m, cmd := openDashboard(m)
+			cmds = append(cmds, cmd)
+
+			return m, tea.Batch(cmds...)
+		case key == "up" || key == "down":
+			// the table has its own use for the arrows once focused
+			recalling := m.textInput.Value() == "" || m.historyPosition > 0
+			if m.textInput.Focused() && !m.table.Focused() && recalling {
+				m = recallSearch(m, key == "up")
+			}
+		default:
+			m.notice = ""
+			m.historyPosition = 0
+			m = filterTable(m)
+		}
+	case postcodeMsg:
+		// ignore lookups for a postcode that has since been edited away
+		if !strings.EqualFold(strings.TrimSpace(m.textInput.Value()), msg.postcode) {
+			break
+		}
+
+		if msg.err != nil {
+			slog.Warn("postcode lookup failed", "err", msg.err)
+			m.notice = msg.err.Error() + ", searching names instead"
+			break
+		}
+
+		m.notice = "Sites nearest " + strings.ToUpper(msg.postcode)
+		m = showRows(m, rowsByDistance(m.allRows, m.siteCoords, msg.lat, msg.lon))
+		m.table.GotoTop()
+		m = focusTable(m)
+	case geolocationMsg:
+		m = showNearest(m, msg)
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		m, cmd = updateSearchMouse(msg, m)
+		cmds = append(cmds, cmd)
+	}
+
+	m, cmd := watchHighlight(m)
+	cmds = append(cmds, cmd)
+
+	return m, tea.Batch(cmds...)
+}
+
+// move focus from the search input to the results
+func focusTable(m model) model {
+	m.textInput.Blur()
+	m.table.Focus()
+	m.table.SetStyles(tableStyleFocussed)
+
+	return m
+}
+
+// make the selected row the site opened on launch
+func setHome(m model) model {
+	row := m.table.SelectedRow()
+	if row == nil {
+		return m
+	}
+
+	if err := saveHome(row[idColumn]); err != nil {
+		m.notice = "Couldn't save your home location: " + err.Error()
+		return m
+	}
+
+	m.notice = "Home set to " + row[nameColumn]
+
+	return m
+}
+
+// open the location under the table's cursor, remembering the search
+// that found it, the table is empty when nothing matched the search
+func chooseSelectedRow(m model) (model, tea.Cmd) {
+	row := m.table.SelectedRow()
+	if row == nil {
+		m.notice = noMatchesNotice
+		return m, nil
+	}
+
+	m = rememberSearch(m, m.textInput.Value())
+
+	return chooseLocation(m, row[idColumn])
+}
+
+// open a site typed in by id rather than picked from the table, as long
+// as it's in the sitelist
+func chooseSiteId(m model, id string) (model, tea.Cmd) {
+	if _, err := strconv.Atoi(id); err != nil {
+		m.notice = "Site ids are numbers, e.g. " + idPrefix + "310002"
+		return m, nil
+	}
+
+	if !slices.ContainsFunc(m.allRows, func(row table.Row) bool { return row[idColumn] == id }) {
+		m.notice = "There's no site with the id " + id
+		return m, nil
+	}
+
+	m = rememberSearch(m, m.textInput.Value())
+	m.textInput.Blur()
+
+	return chooseLocation(m, id)
+}
+
+// push a query onto the front of the search history, moving it there
+// if it was already searched for
+func rememberSearch(m model, query string) model {
+	query = strings.TrimSpace(query)
+	m.historyPosition = 0
+	if query == "" {
+		return m
+	}
+
+	history := []string{query}
+	for _, previous := range m.searchHistory {
+		if previous != query && len(history) < maxSearchHistory {
+			history = append(history, previous)
+		}
+	}
+	m.searchHistory = history
+
+	return m
+}
+
+// step back through older searches, or forward towards an empty input
+func recallSearch(m model, older bool) model {
+	if older {
+		m.historyPosition = min(m.historyPosition+1, len(m.searchHistory))
+	} else {
+		m.historyPosition = max(m.historyPosition-1, 0)
+	}
+
+	query := ""
+	if m.historyPosition > 0 {
+		query = m.searchHistory[m.historyPosition-1]
+	}
+
+	m.textInput.SetValue(query)
+	m.textInput.CursorEnd()
+	m.notice = ""
+
+	return filterTable(m)
+}
+
+// empty the search input and list every site again, alphabetically
+func clearSearch(m model) model {
+	m.textInput.Reset()
+	m.notice = ""
+	m.historyPosition = 0
+	m.sortColumn = nameColumn
+	m.sortDescending = false
+
+	m = layoutTable(m)
+	m = filterTable(m)
+	m.table.GotoTop()
+
+	return m
+}
+
+// refill the table from the search input, fuzzy matches keep their
+// ranking and only unqueried results follow the chosen sort order
+func filterTable(m model) model {
+	var query string
+	m.regionFilter, query = parseSearchInput(m.textInput.Value())
+
+	candidates, names := m.allRows, m.placenames
+	if m.regionFilter != "" {
+		candidates = filterByRegion(m.allRows, m.regionFilter)
+		names = nil
+		for _, row := range candidates {
+			names = append(names, row[nameColumn])
+		}
+	}
+
+	if len(query) > 0 {
+		m = showRows(m, rankMatches(query, candidates, names, m.searchDistance, m.searchResults))
+	} else {
+		m = showRows(m, sortRows(candidates, m.sortColumn, m.sortDescending))
+	}
+
+	return m
+}
+
+// the rows whose names fuzzy match query, best match first, names holds
+// each row's name in the same order. Matches further than maxDistance
+// from the query are dropped and at most limit kept, 0 for no cap
+func rankMatches(query string, rows Rows, names []string, maxDistance, limit int) Rows {
+	matchedNames := fuzzy.RankFindFold(query, names)
+	sort.Sort(matchedNames)
+
+	var matches Rows
+	for _, rankedMatch := range matchedNames {
+		if maxDistance > 0 && rankedMatch.Distance > maxDistance {
+			// they're sorted by distance, so the rest are too
+			break
+		}
+		if limit > 0 && len(matches) >= limit {
+			break
+		}
+
+		matches = append(matches, rows[rankedMatch.OriginalIndex])
+	}
+
+	return matches
+}
+
+// split a search like "region:se brighton" into its region filter and
+// the placename query that follows it
+func parseSearchInput(input string) (region string, query string) {
+	input = strings.TrimLeft(input, " ")
+
+	if !strings.HasPrefix(strings.ToLower(input), regionPrefix) {
+		return "", input
+	}
+
+	region, query, _ = strings.Cut(input[len(regionPrefix):], " ")
+
+	return strings.ToLower(region), strings.TrimSpace(query)
+}
+
+func filterByRegion(rows Rows, region string) Rows {
+	var filtered Rows
+
+	for _, row := range rows {
+		if strings.Contains(strings.ToLower(row[regionColumn]), region) {
+			filtered = append(filtered, row)
+		}
+	}
+
+	return filtered
+}
+
+func updateLocation(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+
+	var listCmd tea.Cmd
+	m.list, listCmd = m.list.Update(msg)
+	cmds = append(cmds, listCmd)
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter":
+			var cmd tea.Cmd
+			m, cmd = openForecast(m)
+			cmds = append(cmds, cmd)
+		case "r":
+			if m.observing || m.loading {
+				break
+			}
+
+			// switch forecast list resolution, the model passed to the
+			// fetch is a copy so the change only sticks once it succeeds
+			toggled := m
+			if m.forecastResolution == dailyResolution {
+				toggled.forecastResolution = threeHourlyResolution
+			} else {
+				toggled.forecastResolution = dailyResolution
+			}
+
+			m.notice = "Loading…"
+			cmds = append(cmds, fetchSiteData(toggled, fetchToggle))
+		case "w":
+			m.summaryChosen = true
+			m.summaryOffset = 0
+		case "p":
+			if m.loading || m.observing || len(m.list.Items()) == 0 {
+				break
+			}
+
+			return pinLocation(m), tea.Batch(cmds...)
+		case "x":
+			if m.compareId != "" {
+				m = clearComparison(m)
+				m.notice = "Stopped comparing"
+			}
+		case "+", "-":
+			if m.loading {
+				break
+			}
+
+			m, cmd := changeDays(m, msg.String() == "+")
+			cmds = append(cmds, cmd)
+
+			return m, tea.Batch(cmds...)
+		case "R":
+			m, cmd := refreshNow(m)
+			cmds = append(cmds, cmd)
+
+			return m, tea.Batch(cmds...)
+		case "a":
+			if !m.loading {
+				m = openDay(m)
+			}
+		case "v":
+			if !m.loading {
+				m = toggleCarousel(m)
+			}
+		case "0", "1", "2", "3":
+			if m.loading {
+				break
+			}
+
+			m, cmd := setDateFilter(m, dateFilterKeys[msg.String()])
+			cmds = append(cmds, cmd)
+
+			return m, tea.Batch(cmds...)
+		case "e":
+			m, cmd := toggleExpanded(m)
+			cmds = append(cmds, cmd)
+
+			return m, tea.Batch(cmds...)
+		case "n":
+			if m.loading {
+				break
+			}
+
+			m.notice = "Loading…"
+			cmds = append(cmds, fetchRegionalText(m))
+		case "o":
+			m, cmd := toggleObservations(m)
+			cmds = append(cmds, cmd)
+
+			return m, tea.Batch(cmds...)
+		case "esc":
+			m = returnToSearch(m)
+		}
+	case tea.MouseMsg:
+		var cmd tea.Cmd
+		m, cmd = updateLocationMouse(msg, m)
+		cmds = append(cmds, cmd)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// show one day more or less, clamped between a single day and all of
+// the days available
+func changeDays(m model, more bool) (model, tea.Cmd) {
+	available := len(m.siteData.Site.Info.Location.Periods)
+	if available == 0 {
+		return m, nil
+	}
+
+	days := available
+	if m.maxDays > 0 {
+		days = min(m.maxDays, available)
+	}
+
+	if more {
+		days++
+	} else {
+		days--
+	}
+	days = max(1, days)
+
+	if days >= available {
+		m.maxDays = 0
+		m.notice = "Showing all days"
+	} else {
+		m.maxDays = days
+		m.notice = fmt.Sprintf("Showing %d of %d days", days, available)
+	}
+
+	index := m.list.Index()
+	cmd := m.list.SetItems(getForecastListItems(m))
+	m.list.Select(min(index, max(0, len(m.list.Items())-1)))
+
+	return m, cmd
+}
+
+// show the detail of the selected forecast, if there is one
+func openForecast(m model) (model, tea.Cmd) {
+	item, ok := m.list.SelectedItem().(forecastItem)
+	if !ok {
+		return m, nil
+	}
+
+	m.forecastChosen = true
+
+	periodIndex, forecastIndex := item.Position()
+	forecast := m.siteData.Site.Info.Location.Periods[periodIndex].Forecasts[forecastIndex]
+
+	m.forecastData = getForecastData(m, forecast)
+	m.seenBefore, m.changesKey = nil, ""
+	if date, err := parsePeriodDate(m.siteData.Site.Info.Location.Periods[periodIndex].Date); err == nil {
+		m.forecastData.WeatherCode = slotWeatherCode(m, date, m.forecastData)
+		return checkChanges(m, date, forecast.Time)
+	}
+
+	return m, nil
+}
+
+// show the forecast before or after the one being viewed, moving the
+// list's selection with it so going back lands on it
+func stepForecast(m model, step int) (model, tea.Cmd) {
+	index := max(0, min(m.list.Index()+step, len(m.list.Items())-1))
+	if index == m.list.Index() {
+		return m, nil
+	}
+
+	m.list.Select(index)
+	m.notice = ""
+
+	return openForecast(m)
+}
+
+// switch between forecasts and observations for the chosen site
+func toggleObservations(m model) (model, tea.Cmd) {
+	if m.loading {
+		return m, nil
+	}
+
+	toggled := m
+	toggled.observing = !m.observing
+
+	if forecasts, _ := siteDataKinds(m.allRows, m.locationId); !forecasts {
+		m.notice = "Only observations are available for this site"
+		return m, nil
+	}
+
+	if toggled.observing {
+		// the observation sitelist is only needed once
+		if m.observationSites == nil {
+			m.notice = "Loading…"
+			return m, fetchObservationSites(m)
+		}
+
+		if !m.observationSites[m.locationId] {
+			m.notice = "No observations are available for this site"
+			return m, nil
+		}
+	}
+
+	m.notice = "Loading…"
+
+	return m, fetchSiteData(toggled, fetchToggle)
+}
+
+func updateError(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "enter":
+			// without the sitelist there's no search to go back to
+			if len(m.allRows) > 0 {
+				m = returnToSearch(m)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func updateForecast(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			m.forecastChosen = false
+			m.notice = ""
+		case "b":
+			m = returnToSearch(m)
+		case "R":
+			return refreshNow(m)
+		case "y":
+			return m, copyForecast(m)
+		case "i":
+			m.debug = !m.debug
+		case "left", "h":
+			return stepForecast(m, -1)
+		case "right", "l":
+			return stepForecast(m, 1)
+		}
+	}
+
+	return m, nil
+}
+
+func (m model) View() string {
+	var s string
+
+	if m.enteringKey {
+		s += keyEntryView(m)
+	} else if m.loadingSites {
+		s += splashView(m)
+	} else if m.err != nil {
+		s += errorView(m)
+	} else if m.settingsChosen {
+		s += settingsView(m)
+	} else if m.dashboardChosen {
+		s += dashboardView(m)
+	} else if m.forecastChosen {
 		s += forecastView(m)
+	} else if m.summaryChosen {
+		s += summaryView(m)
+	} else if m.regionalChosen {
+		s += regionalView(m)
+	} else if m.comparing {
+		s += compareView(m)
+	} else if m.dayChosen {
+		s += dayView(m)
 	} else if m.locationChosen {
 		s += locationView(m)
 	} else {
 		s += searchView(m)
 	}
 
+	if noColor {
+		return stripColor(s)
+	}
+
 	return s
 }
 
 func searchView(m model) string {
-	renderedTable := borderStyle.Render(m.table.View())
+	frame := borderStyle
+	if isCompact(m.width) {
+		frame = lipgloss.NewStyle()
+	}
+
+	renderedTable := frame.Render(stripeTable(m))
 
 	// set the text input width to match the table
 	// the text input width is not the full rendered width,
 	// just the number of chars in the input field type so
-	// we need to adjust by the width of the prompt and border area
-	textInputPadding := 5
+	// we need to adjust by the width of the prompt, cursor and border area
+	textInputPadding := 3 + frameWidth(frame)
 	m.textInput.Width = lipgloss.Width(renderedTable) - textInputPadding
 
-	components := borderStyle.Render(m.textInput.View()) + "\n" + renderedTable
+	components := frame.Render(m.textInput.View()) + "\n"
+	if m.notice != "" {
+		components += footerView(m) + "\n"
+	}
+	components += renderedTable + "\n" + searchHint(m)
 
 	// horizontally center the entire view
 	gap := strings.Repeat(" ", max(0, (m.width-lipgloss.Width(components))/2))
 	return lipgloss.JoinHorizontal(lipgloss.Left, gap, components)
 }
 
+// whether keys are going into a text input, where q is typed rather
+// than quitting and ctrl+c is the way out
+func typing(m model) bool {
+	if m.enteringKey {
+		return true
+	}
+
+	searching := !m.loadingSites && m.err == nil && !m.dashboardChosen && !m.locationChosen
+
+	return searching && m.textInput.Focused()
+}
+
+// what esc does depends on whether the input or the table is focused,
+// and q can only quit once it isn't being typed
+func searchHint(m model) string {
+	hint := "enter to pick from the results, esc to clear, ctrl+c to quit"
+	if m.table.Focused() {
+		hint = "enter to open, esc to edit the search, q to quit"
+	}
+
+	return lipgloss.NewStyle().Foreground(paletteColor(grey)).Render(hint)
+}
+
+// subtle indicator of when the data was last auto-refreshed
+func updatedView(m model) string {
+	if m.lastUpdated.IsZero() {
+		return ""
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(paletteColor(grey)).
+		Faint(true).
+		Render("updated " + clockTime(m.lastUpdated, m.twelveHour))
+}
+
+func serviceDownNotice(until time.Time) string {
+	return "Met Office service appears to be down — trying again after " + until.Local().Format("15:04")
+}
+
+func footerView(m model) string {
+	notice := m.notice
+	// refreshes will fail until the breaker closes, so say why up front
+	if until, down := serviceDown(m); notice == "" && down {
+		notice = serviceDownNotice(until)
+	}
+
+	if notice != "" {
+		return lipgloss.NewStyle().
+			Foreground(paletteColor(yellow)).
+			Render(notice)
+	}
+
+	return updatedView(m)
+}
+
+// position of the cursor within the list, e.g. "3 of 16"
+func positionView(li list.Model) string {
+	total := len(li.Items())
+	if total == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d of %d", li.Index()+1, total)
+}
+
+// the API's timestamps sometimes have a trailing "Z" and sometimes
+// don't, either way they're UTC
+func parseDataDate(s string) (time.Time, error) {
+	layouts := []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04"}
+
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, strings.TrimSpace(s), time.UTC)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognised data date %q", s)
+}
+
+// period dates are UTC days, usually with a trailing "Z"
+func parsePeriodDate(s string) (time.Time, error) {
+	layouts := []string{"2006-01-02Z", "2006-01-02", time.RFC3339}
+
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, strings.TrimSpace(s), time.UTC)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognised period date %q", s)
+}
+
+// the local time of a slot given in minutes past the period's midnight,
+// daily slots are "Day" or "Night" and have no time to convert
+func localSlotTime(date time.Time, slot string) (time.Time, bool) {
+	minutes, err := strconv.Atoi(slot)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return date.Add(time.Duration(minutes) * time.Minute).Local(), true
+}
+
+// a time of day given in minutes past midnight, e.g. "15:00" or "3:00pm"
+func formatForecastTime(minutes int, twelveHour bool) string {
+	hour, minute := minutes/60%24, minutes%60
+	if !twelveHour {
+		return fmt.Sprintf("%02d:%02d", hour, minute)
+	}
+
+	suffix := "am"
+	if hour >= 12 {
+		suffix = "pm"
+	}
+
+	if hour%12 == 0 {
+		return fmt.Sprintf("12:%02d%s", minute, suffix)
+	}
+
+	return fmt.Sprintf("%d:%02d%s", hour%12, minute, suffix)
+}
+
+func clockTime(t time.Time, twelveHour bool) string {
+	return formatForecastTime(t.Hour()*60+t.Minute(), twelveHour)
+}
+
+// when the Met Office issued the data, highlighted if it's getting old
+func issuedView(m model) string {
+	issued, err := parseDataDate(m.siteData.Site.Info.Date)
+	if err != nil {
+		return ""
+	}
+
+	issued = issued.Local()
+	now := clock()
+
+	text := "Data issued " + clockTime(issued, m.twelveHour)
+	if issued.YearDay() != now.YearDay() || issued.Year() != now.Year() {
+		text += issued.Format(" on Mon 02 Jan")
+	}
+
+	// a newer run has come out since this was fetched
+	if latest, ok := latestDataDate(m.forecastResolution); ok && !m.observing && latest.After(issued) {
+		text += ", newer data out at " + clockTime(latest.Local(), m.twelveHour) + ", press R to refresh"
+	}
+
+	style := lipgloss.NewStyle().Foreground(paletteColor(grey))
+	if now.Sub(issued) > staleDataThreshold {
+		style = style.Foreground(paletteColor(yellow))
+	}
+
+	return style.Render(text)
+}
+
+// the active location and settings, shown at the bottom of the screen
+func statusBar(m model) string {
+	res := string(m.forecastResolution)
+	if m.observing {
+		res = "observations"
+	}
+
+	location := m.siteData.Site.Info.Location.Name
+	if location == "" {
+		location = m.locationId
+	}
+
+	items := []string{location, res, string(m.tempUnit), string(m.windUnit)}
+	if m.dateFilter != noFilter {
+		items = append(items, m.dateFilter.String())
+	}
+
+	h, _ := listStyle.GetFrameSize()
+	width := max(0, m.width-h)
+
+	return lipgloss.NewStyle().
+		Foreground(paletteColor(black)).
+		Background(paletteColor(purple)).
+		Width(width).
+		MaxWidth(width).
+		Inline(true).
+		Render(" " + strings.Join(items, " │ "))
+}
+
+// whether a request is being held back to stay under the DataPoint
+// rate limit
+func throttled(m model) bool {
+	limiter := data.DefaultClient.Limiter
+	return m.fetcher == data.Source(data.DefaultClient) && limiter != nil && limiter.Waiting()
+}
+
+// whether requests are being turned away after repeated failures, and
+// until when
+func serviceDown(m model) (time.Time, bool) {
+	breaker := data.DefaultClient.Breaker
+	if m.fetcher != data.Source(data.DefaultClient) || breaker == nil {
+		return time.Time{}, false
+	}
+
+	return breaker.Open()
+}
+
+// explain the HTTP statuses the DataPoint API commonly responds with
+func interpretStatus(code int) string {
+	switch {
+	case code == http.StatusForbidden:
+		return "Your Met Office API key was rejected (HTTP 403) — check MET_OFFICE_API_KEY"
+	case code == http.StatusNotFound:
+		return "Location not found"
+	case code == http.StatusTooManyRequests:
+		return "Too many requests to the Met Office API (HTTP 429) — try again later"
+	case code >= http.StatusInternalServerError:
+		return fmt.Sprintf("The Met Office API is having problems (HTTP %d) — try again later", code)
+	default:
+		return fmt.Sprintf("Unexpected response from the Met Office API (HTTP %d)", code)
+	}
+}
+
+// a user facing description of an error, explaining bad responses
+func describeError(err error) string {
+	var statusErr *data.StatusError
+	if errors.As(err, &statusErr) {
+		return interpretStatus(statusErr.Code)
+	}
+
+	var exhaustedErr *data.KeysExhaustedError
+	if errors.As(err, &exhaustedErr) {
+		return "All of your Met Office API keys are rate limited — try again after " + exhaustedErr.Until.Local().Format("15:04")
+	}
+
+	var breakerErr *data.BreakerOpenError
+	if errors.As(err, &breakerErr) {
+		return serviceDownNotice(breakerErr.Until)
+	}
+
+	if errors.Is(err, errNoTimesteps) {
+		return "The Met Office has no forecasts available right now — try again shortly"
+	}
+
+	return "Something went wrong: " + err.Error()
+}
+
+func splashView(m model) string {
+	return listStyle.Render(m.spinner.View() + " Loading locations…" + sitelistProgress(m) + "\n\nPress ctrl+c to quit")
+}
+
+// e.g. " 1.2 MB of 3.4 MB (35%)", or just what's arrived when the size
+// isn't known
+func sitelistProgress(m model) string {
+	switch {
+	case m.sitelistRead == 0:
+		return ""
+	case m.sitelistTotal <= 0:
+		return " " + formatBytes(m.sitelistRead)
+	default:
+		percent := m.sitelistRead * 100 / m.sitelistTotal
+		return fmt.Sprintf(" %s of %s (%d%%)", formatBytes(m.sitelistRead), formatBytes(m.sitelistTotal), percent)
+	}
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1f MB", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%d KB", n/1_000)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+func errorView(m model) string {
+	message := lipgloss.NewStyle().
+		Foreground(paletteColor(pink)).
+		Render(describeError(m.err))
+
+	if len(m.allRows) == 0 {
+		return listStyle.Render(wrapText(m, message+"\n\nPress ctrl+c to quit"))
+	}
+
+	return listStyle.Render(wrapText(m, message+"\n\nPress esc to go back to the search or q to quit"))
+}
+
+// word-wrap text to fit inside listStyle's margins at the current width
+func wrapText(m model, text string) string {
+	h, _ := listStyle.GetFrameSize()
+	if m.width <= h {
+		return text
+	}
+
+	return lipgloss.NewStyle().Width(m.width - h).Render(text)
+}
+
 func locationView(m model) string {
-	return listStyle.Render(m.list.View())
+	if m.loading {
+		text := "Loading forecast…"
+		if throttled(m) {
+			text += lipgloss.NewStyle().Foreground(paletteColor(grey)).Render(" rate limited, waiting…")
+		}
+
+		return listStyle.Render(text + "\n\nPress esc to cancel")
+	}
+
+	if len(m.list.Items()) == 0 {
+		name := m.siteData.Site.Info.Location.Name
+		text := "No forecast data available for this location"
+		if m.observing {
+			text = "No observations available for this location"
+		}
+		if m.dateFilter != noFilter {
+			text = "Nothing to show for " + m.dateFilter.String() + ", press 0 for all days"
+		}
+
+		if name != "" {
+			text = name + "\n\n" + text
+		}
+
+		return listStyle.Render(wrapText(m, text+"\n\nPress esc to go back to the search or q to quit"))
+	}
+
+	if m.carousel {
+		return carouselView(m)
+	}
+
+	footer := positionView(m.list)
+	if extra := footerView(m); extra != "" {
+		footer += "  " + extra
+	}
+
+	header := issuedView(m)
+	if spark := temperatureSparkline(m); spark != "" {
+		header += "  " + spark
+	}
+	if banner := warningsBanner(m); banner != "" {
+		header = banner + "\n" + header
+	}
+
+	return listStyle.Render(header + "\n" + m.list.View() + "\n" + footer + "\n" + statusBar(m))
+}
+
+// a labelled value in the forecast's details
+type keyValue struct {
+	label, value string
+}
+
+// one line for each field, the values lined up in a column after the
+// longest label
+func renderKeyValues(fields []keyValue) string {
+	labelWidth := 0
+	for _, field := range fields {
+		labelWidth = max(labelWidth, lipgloss.Width(field.label))
+	}
+
+	labelStyle := lipgloss.NewStyle().Foreground(paletteColor(grey)).Width(labelWidth + 2)
+
+	var text string
+	for _, field := range fields {
+		text += labelStyle.Render(field.label) + field.value + "\n"
+	}
+
+	return text
 }
 
 func forecastView(m model) string {
 	period := m.list.SelectedItem().(forecastItem).Title()
 	title := m.siteData.Site.Info.Location.Name + " - " + period
+	if m.observing {
+		title += " (observed)"
+	}
+
+	width := barWidth(m.width)
+	if m.debug {
+		width = barWidth(m.width - debugCodesWidth)
+	}
+
+	// in debug mode each label is followed by the params behind it
+	meta := m.siteData.Site.MetaInfo
+	codes := paramCodesFor(m.forecastResolution, m.forecastData.Time)
+	units := unitsForResolution(meta, m.forecastResolution)
+	field := func(label string, value string, params ...string) keyValue {
+		if m.debug {
+			label += " " + describeCodes(meta, params...)
+		}
+		return keyValue{label, value}
+	}
+
+	fields := []keyValue{field("Conditions", describeCode(m.forecastData.WeatherCode, m.language), "W")}
+
+	// observations don't include a chance of rain
+	if m.forecastData.Precipitation != "" {
+		fields = append(fields, field("Chance of rain", renderPercent(m.forecastData.Precipitation, units["precipitation"], width), codes.precipitation))
+	}
+
+	fields = append(fields, field("Temperature", renderTemp(m.forecastData.Temperature, m.tempUnit), codes.temp))
+
+	// daily night forecasts have no UV but do have a feels like value
+	if m.forecastData.FeelsLikeTemp != "" {
+		fields = append(fields, field("Feels like", renderFeelsLike(m.forecastData, m.tempUnit), codes.feelsLike))
+	}
+
+	if label, c := uvCategory(m.forecastData.UV); label != "" {
+		text := m.forecastData.UV + " (" + label + ")"
+		fields = append(fields, field("UV", lipgloss.NewStyle().Foreground(paletteColor(c)).Render(text), "U"))
+	}
+
+	fields = append(fields,
+		field("Wind", renderWind(m.forecastData, m.windUnit), "D", "S"),
+		field("Gusts", renderGusts(m.forecastData, m.windUnit), codes.gust),
+	)
+
+	if gustiness := renderGustiness(m.forecastData, m.windUnit); gustiness != "" {
+		fields = append(fields, field("Gustiness", gustiness, "S", codes.gust))
+	}
+
+	fields = append(fields,
+		field("Humidity", renderHumidity(m.forecastData, units["humidity"], width), codes.humidity),
+		field("Visibility", describeVisibility(m.forecastData.Visibility), "V"),
+	)
 
-	// TODO: prettier rendering
-	forecast := data.WeatherCodes[m.forecastData.WeatherCode] + "\n" +
-		m.forecastData.Precipitation + "% chance of rain" + "\n" +
-		m.forecastData.Temperature + "°C" + "\n" +
-		m.forecastData.WindSpeed + "mph Wind" + "\n" +
-		m.forecastData.WindDirection + " Wind Direction" + "\n" +
-		m.forecastData.Humidity + "% Humidity" + "\n"
+	if m.forecastData.Pressure != "" {
+		fields = append(fields, field("Pressure", m.forecastData.Pressure+units["pressure"], "P"))
+	}
+
+	if m.forecastData.DewPoint != "" {
+		fields = append(fields, field("Dew point", formatTemp(m.forecastData.DewPoint, m.tempUnit), "Dp"))
+	}
+
+	forecast := renderKeyValues(fields)
+
+	header := title + "\n" + issuedView(m)
+	if banner := warningsBanner(m); banner != "" {
+		header = banner + "\n" + header
+	}
+	if tips := suggestions(m.forecastData); len(tips) > 0 {
+		header += "\n" + lipgloss.NewStyle().Foreground(paletteColor(green)).Render(strings.Join(tips, " · "))
+	}
+	if m.seenBefore != nil {
+		if changes := diffForecasts(*m.seenBefore, m.forecastData, m.tempUnit, m.windUnit); len(changes) > 0 {
+			header += "\n" + lipgloss.NewStyle().Foreground(paletteColor(amber)).Render("Since last check: "+strings.Join(changes, ", "))
+		}
+	}
+
+	text := wrapText(m, header+"\n\n"+forecast+"\n"+footerView(m))
+	text += "\n" + lipgloss.NewStyle().
+		Foreground(paletteColor(grey)).
+		Render("←/→ other times, esc the list, b the search, i field codes, q to quit")
 
-	text := title + "\n\n" + forecast
+	// keep the status bar at the bottom of the screen
+	_, v := listStyle.GetFrameSize()
+	text = lipgloss.PlaceVertical(m.height-v-1, lipgloss.Top, text)
 
-	return listStyle.Render(text)
+	return listStyle.Render(text + "\n" + statusBar(m))
 }
 
 func main() {
-	apiKey = getApiKey()
+	os.Exit(run())
+}
+
+// everything main does, returning the exit code so the log file can be
+// closed on the way out
+func run() int {
+	autoRefresh := flag.Bool("refresh", false, "periodically re-fetch the forecast being viewed")
+	refreshMinutes := flag.Int("refresh-interval", defaultRefreshMinutes, "minutes between auto-refreshes")
+	offline := flag.Bool("offline", false, "use bundled sample data instead of the Met Office API")
+	jsonOutput := flag.Bool("json", false, "print the forecast for -location as JSON and exit")
+	pngPath := flag.String("png", "", "save a card showing the forecast under way at -location to this PNG file and exit")
+	listQuery := flag.String("list", "", "print the name, id and region of the sites matching a search, tab separated, and exit")
+	noHome := flag.Bool("no-home", false, "start at the search rather than the home location from the config")
+	nearest := flag.Bool("nearest", false, "list the sites nearest your approximate location, found by sending your IP address to ipapi.co")
+	logPath := flag.String("log", defaultLogPath(), "file to write logs to, empty to turn logging off")
+	verbose := flag.Bool("verbose", false, "include debug messages in the log")
+	monochrome := flag.Bool("no-color", false, "draw everything in the terminal's default colours, also set by the NO_COLOR env var")
+	metricsPath := flag.String("metrics", "", "on exit write request counts and timings in the Prometheus text format to this file, - for stderr")
+	resume := flag.Bool("resume", false, "pick up at the location and settings from when you last quit")
+	printVersion := flag.Bool("version", false, "print the version, commit and build date and exit")
+	debugCodes := flag.Bool("debug", false, "label each forecast detail with the Met Office field codes it came from, toggled with i")
+	caCertPath := flag.String("ca-cert", os.Getenv(caCertEnv), "PEM file of a CA certificate to trust as well as the system's, e.g. a proxy's, also set by the "+caCertEnv+" env var")
+
+	// these override the config file, which overrides the defaults
+	var overrides Config
+	flag.StringVar(&overrides.Theme, "theme", "", "colour theme to use, one of: "+themeNames())
+	flag.StringVar(&overrides.Location, "location", "", "site id to open, or to forecast with -json")
+	flag.StringVar(&overrides.Resolution, "resolution", "", "forecast resolution, daily or 3hourly")
+	flag.IntVar(&overrides.Days, "days", 0, "how many days of forecasts to list, 0 for all")
+	flag.IntVar(&overrides.SearchResults, "search-results", 0, fmt.Sprintf("the most matches a search lists, 0 for %d", defaultSearchResults))
+	flag.IntVar(&overrides.SearchDistance, "search-distance", 0, fmt.Sprintf("how loose a match a search lists, a distance growing with the letters of a name not in the search, 0 for %d", defaultSearchDistance))
+	flag.StringVar(&overrides.Language, "lang", "", "language for weather descriptions, one of: "+languageNames())
+	flag.Parse()
+
+	if *printVersion {
+		fmt.Println(versionInfo(buildInfo()))
+		return 0
+	}
+
+	logFile, err := setupLogging(*logPath, *verbose)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Not logging:", err)
+		logFile, _ = setupLogging("", false)
+	}
+	defer logFile.Close()
+
+	slog.Info("starting", "offline", *offline, "json", *jsonOutput)
+
+	cfg, err := loadConfig()
+	if err != nil {
+		slog.Warn("using default settings", "err", err)
+		fmt.Fprintln(os.Stderr, "Using default settings:", err)
+	}
+	if *noHome {
+		cfg.Location = ""
+	}
+	if *resume {
+		cfg = resumeSession(cfg)
+	}
+	cfg = mergeConfig(cfg, overrides, givenFlags())
+
+	if *offline {
+		data.DefaultSource = data.Offline{}
+	} else if keys := getApiKeys(cfg); len(keys) > 0 {
+		apiKey = keys[0]
+		data.DefaultClient.SetKeys(keys)
+	}
+	if cfg.RequestsPerMinute > 0 {
+		data.DefaultClient.Limiter = data.NewLimiter(cfg.RequestsPerMinute)
+	}
+	if *caCertPath != "" {
+		if err := data.DefaultClient.AddCACert(*caCertPath); err != nil {
+			slog.Error("loading CA certificate", "path", *caCertPath, "err", err)
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if *metricsPath != "" {
+		data.DefaultClient.Metrics = &data.Metrics{}
+		defer writeMetrics(*metricsPath, data.DefaultClient.Metrics)
+	}
+
+	if *listQuery != "" {
+		if apiKey == "" && !*offline {
+			fmt.Fprintln(os.Stderr, apiKeyEnv+" env var not set")
+			return 2
+		}
+
+		if err := writeMatchingSites(context.Background(), os.Stdout, model{fetcher: data.DefaultSource}, *listQuery); err != nil {
+			slog.Error("listing sites", "query", *listQuery, "err", err)
+			fmt.Fprintln(os.Stderr, describeError(err))
+			return 1
+		}
 
-	m := initialModel()
-	p := tea.NewProgram(m, tea.WithAltScreen())
+		return 0
+	}
+
+	if *pngPath != "" {
+		if cfg.Location == "" {
+			fmt.Fprintln(os.Stderr, "-png needs a site id given with -location")
+			return 2
+		}
+
+		if apiKey == "" && !*offline {
+			fmt.Fprintln(os.Stderr, apiKeyEnv+" env var not set")
+			return 2
+		}
+
+		m, err := applyConfig(model{fetcher: data.DefaultSource}, cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid settings:", err)
+			return 2
+		}
+
+		if err := writeForecastPNG(context.Background(), *pngPath, m, cfg.Location); err != nil {
+			slog.Error("writing forecast PNG", "location", cfg.Location, "path", *pngPath, "err", err)
+			fmt.Fprintln(os.Stderr, describeError(err))
+			return 1
+		}
+
+		return 0
+	}
+
+	if *jsonOutput {
+		if cfg.Location == "" {
+			fmt.Fprintln(os.Stderr, "-json needs a site id given with -location")
+			return 2
+		}
+
+		if apiKey == "" && !*offline {
+			fmt.Fprintln(os.Stderr, apiKeyEnv+" env var not set")
+			return 2
+		}
+
+		m, err := applyConfig(model{fetcher: data.DefaultSource}, cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Invalid settings:", err)
+			return 2
+		}
+
+		err = writeForecastJSON(context.Background(), os.Stdout, m, cfg.Location, resolution(cfg.Resolution))
+		if err != nil {
+			slog.Error("writing forecast JSON", "location", cfg.Location, "err", err)
+			fmt.Fprintln(os.Stderr, describeError(err))
+			return 1
+		}
+
+		return 0
+	}
+
+	if *monochrome || noColorEnv() {
+		disableColor()
+	}
+
+	m := initialModel(cfg)
+	if apiKey == "" && !*offline && m.err == nil {
+		m = askForApiKey(m)
+	}
+	if *autoRefresh {
+		m.refreshInterval = time.Duration(*refreshMinutes) * time.Minute
+	}
+	m.locateNearby = *nearest && !*offline
+	m.debug = *debugCodes
+	if !*offline {
+		m.seenPath = defaultSeenPath()
+	}
+
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
 	if _, err := p.Run(); err != nil {
-		log.Fatal(err)
+		slog.Error("program exited", "err", err)
+		fmt.Fprintln(os.Stderr, err)
+		return 1
 	}
+
+	return 0
 }
@@ -0,0 +1,1619 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestDescribeCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"1", "Sunny day"},
+		{"30", "Thunder"},
+		{"4", unknownConditions},
+		{"", unknownConditions},
+		{"31", unknownConditions},
+		{"-1", unknownConditions},
+		{"NA", unknownConditions},
+	}
+
+	for _, test := range tests {
+		if got := describeCode(test.code, data.English); got != test.want {
+			t.Errorf("describeCode(%q) = %q, want %q", test.code, got, test.want)
+		}
+	}
+	m, err := applyConfig(model{}, Config{Resolution: "daily", TemperatureUnit: "C", WindUnit: "mph", Theme: "dark", Language: "CY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := describeCode("1", m.language); got != "Diwrnod heulog" {
+		t.Errorf("expected the Welsh description, got %q", got)
+	}
+	if got := describeCode("4", m.language); got != unknownConditions {
+		t.Errorf("expected unused codes to stay unknown in Welsh, got %q", got)
+	}
+
+	if _, err := applyConfig(model{}, Config{Resolution: "daily", TemperatureUnit: "C", WindUnit: "mph", Theme: "dark", Language: "fr"}); err == nil {
+		t.Error("expected an unsupported language to be rejected")
+	}
+}
+
+func TestForecastDataAccessors(t *testing.T) {
+	fd := forecastData{
+		Time:          "540",
+		Temperature:   "-3",
+		FeelsLikeTemp: "-7",
+		WindSpeed:     "12",
+		GustSpeed:     "",
+		Precipitation: "NA",
+	}
+
+	if minutes, err := fd.Minutes(); err != nil || minutes != 540 {
+		t.Errorf("Minutes() = %d, %v, want 540", minutes, err)
+	}
+
+	if temp, err := fd.TemperatureC(); err != nil || temp != -3 {
+		t.Errorf("TemperatureC() = %d, %v, want -3", temp, err)
+	}
+
+	if temp, err := fd.FeelsLikeC(); err != nil || temp != -7 {
+		t.Errorf("FeelsLikeC() = %d, %v, want -7", temp, err)
+	}
+
+	if speed, err := fd.WindSpeedMph(); err != nil || speed != 12 {
+		t.Errorf("WindSpeedMph() = %d, %v, want 12", speed, err)
+	}
+
+	if _, err := fd.GustSpeedMph(); err == nil {
+		t.Error("GustSpeedMph() should fail for an empty value")
+	}
+
+	if _, err := fd.PrecipitationPct(); err == nil {
+		t.Error("PrecipitationPct() should fail for a non-numeric value")
+	}
+}
+
+func TestLocationViewWithNoPeriods(t *testing.T) {
+	m := model{list: setupList(), forecastResolution: dailyResolution}
+	m.siteData.Site.Info.Location.Name = "Nowhere"
+
+	items := getForecastListItems(m)
+	if len(items) != 0 {
+		t.Fatalf("expected no list items, got %d", len(items))
+	}
+	m.list.SetItems(items)
+
+	if view := locationView(m); !strings.Contains(view, "No forecast data available") {
+		t.Errorf("expected an explanation for the empty forecast, got:\n%s", view)
+	}
+
+	// pressing enter on the empty list shouldn't select anything
+	updated, _ := updateLocation(tea.KeyMsg{Type: tea.KeyEnter}, m)
+	if updated.(model).forecastChosen {
+		t.Error("enter on an empty list should not choose a forecast")
+	}
+}
+
+func TestUVCategory(t *testing.T) {
+	tests := []struct {
+		uv    string
+		label string
+		c     color
+	}{
+		{"1", "Low", green},
+		{"2", "Low", green},
+		{"3", "Moderate", yellow},
+		{"5", "Moderate", yellow},
+		{"6", "High", pink},
+		{"7", "High", pink},
+		{"8", "Very High", purple},
+		{"10", "Very High", purple},
+		{"11", "Extreme", purple},
+		{"", "", grey},
+	}
+
+	for _, test := range tests {
+		label, c := uvCategory(test.uv)
+		if label != test.label || c != test.c {
+			t.Errorf("uvCategory(%q) = %q, %d, want %q, %d", test.uv, label, c, test.label, test.c)
+		}
+	}
+}
+
+func TestSelectLocationOffline(t *testing.T) {
+	var next tea.Model = startedModel(defaultConfig(), data.Offline{})
+
+	// focus the table then choose its first row
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	next, _ = next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	m := next.(model)
+	if !m.locationChosen || !m.loading {
+		t.Fatal("expected a location to be loading")
+	}
+
+	next, _ = m.Update(fetchSiteData(m, fetchSelect)())
+	m = next.(model)
+
+	if m.loading || m.err != nil {
+		t.Fatalf("expected the location to load, got error %v", m.err)
+	}
+
+	if len(m.list.Items()) != 10 {
+		t.Errorf("expected 10 daily forecasts, got %d", len(m.list.Items()))
+	}
+
+	// switch to 3hourly
+	m.forecastResolution = threeHourlyResolution
+	next, _ = m.Update(fetchSiteData(m, fetchToggle)())
+	m = next.(model)
+
+	if len(m.list.Items()) != 37 {
+		t.Errorf("expected 37 3hourly forecasts, got %d", len(m.list.Items()))
+	}
+}
+
+func TestGetForecastData(t *testing.T) {
+	forecast := data.Forecast{
+		WeatherCode:   "7",
+		WindDirection: "SW",
+		WindSpeed:     "9",
+		Visibility:    "GO",
+		Day: data.Day{
+			UV:            "3",
+			Precipitation: "40",
+			Humidity:      "70",
+			GustSpeed:     "20",
+			Temperature:   "12",
+			FeelsLikeTemp: "10",
+		},
+		Night: data.Night{
+			Precipitation: "60",
+			Humidity:      "90",
+			GustSpeed:     "15",
+			Temperature:   "4",
+			FeelsLikeTemp: "1",
+		},
+		Hourly: data.Hourly{
+			UV:            "1",
+			Precipitation: "25",
+			Humidity:      "80",
+			GustSpeed:     "18",
+			Temperature:   "9",
+			FeelsLikeTemp: "7",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		resolution resolution
+		time       string
+		want       forecastData
+	}{
+		{
+			name:       "daily day",
+			resolution: dailyResolution,
+			time:       "Day",
+			want: forecastData{
+				Time: "Day", UV: "3", Precipitation: "40", Humidity: "70",
+				GustSpeed: "20", Temperature: "12", FeelsLikeTemp: "10",
+			},
+		},
+		{
+			name:       "daily night",
+			resolution: dailyResolution,
+			time:       "Night",
+			want: forecastData{
+				Time: "Night", UV: "", Precipitation: "60", Humidity: "90",
+				GustSpeed: "15", Temperature: "4", FeelsLikeTemp: "1",
+			},
+		},
+		{
+			name:       "3hourly",
+			resolution: threeHourlyResolution,
+			time:       "540",
+			want: forecastData{
+				Time: "540", UV: "1", Precipitation: "25", Humidity: "80",
+				GustSpeed: "18", Temperature: "9", FeelsLikeTemp: "7",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		f := forecast
+		f.Time = test.time
+
+		want := test.want
+		want.WeatherCode, want.WindDirection, want.WindSpeed, want.Visibility = "7", "SW", "9", "GO"
+
+		got := getForecastData(model{forecastResolution: test.resolution}, f)
+		if got != want {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, want)
+		}
+	}
+}
+
+func TestBackToSearchFromForecast(t *testing.T) {
+	m := model{
+		list:           setupList(),
+		table:          setupTable(nil, 0),
+		textInput:      setupTextInput(),
+		locationChosen: true,
+		forecastChosen: true,
+	}
+
+	next, _ := updateForecast(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")}, m)
+	m = next.(model)
+
+	if m.locationChosen || m.forecastChosen {
+		t.Error("expected to be back on the search view")
+	}
+
+	if m.textInput.Focused() || !m.table.Focused() {
+		t.Error("expected the table to have focus")
+	}
+}
+
+func TestStepForecast(t *testing.T) {
+	m := runCmd(chooseLocation(startedModel(defaultConfig(), data.Offline{}), "310002"))
+	m, _ = openForecast(m)
+
+	press := func(keys ...tea.KeyMsg) {
+		for _, key := range keys {
+			next, _ := m.Update(key)
+			m = next.(model)
+		}
+	}
+
+	left := tea.KeyMsg{Type: tea.KeyLeft}
+	right := tea.KeyMsg{Type: tea.KeyRight}
+
+	// already at the first forecast
+	press(left)
+	if m.list.Index() != 0 || !m.forecastChosen {
+		t.Fatalf("expected to stay on the first forecast, got %d", m.list.Index())
+	}
+
+	press(right, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	forecast := m.siteData.Site.Info.Location.Periods[1].Forecasts[0]
+	if m.list.Index() != 2 || m.forecastData.Temperature != forecast.Day.Temperature {
+		t.Errorf("expected the third forecast, got %d with %+v", m.list.Index(), m.forecastData)
+	}
+
+	last := len(m.list.Items()) - 1
+	m.list.Select(last)
+	press(right)
+	if m.list.Index() != last {
+		t.Errorf("expected to stay on the last forecast, got %d", m.list.Index())
+	}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("h")}, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.forecastChosen || m.list.Index() != last-1 {
+		t.Errorf("expected to return to the list at %d, got %d", last-1, m.list.Index())
+	}
+}
+
+func TestSparseSiteData(t *testing.T) {
+	m := model{list: setupList(), forecastResolution: threeHourlyResolution}
+	m.siteData.Site.Info.Location.Name = "Sparse"
+	m.siteData.Site.Info.Location.Periods = []data.Period{
+		{Date: "not a date"},
+		{Date: "2024-01-10Z", Forecasts: []data.Forecast{{Time: "not a time"}, {}}},
+	}
+
+	items := getForecastListItems(m)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 list items, got %d", len(items))
+	}
+	m.list.SetItems(items)
+	m.forecastData = getForecastData(m, m.siteData.Site.Info.Location.Periods[1].Forecasts[1])
+
+	if m.forecastData.Temperature != missingValue || m.forecastData.WindSpeed != missingValue {
+		t.Errorf("expected placeholders for missing values, got %+v", m.forecastData)
+	}
+
+	m.list.Select(1)
+	view := forecastView(m)
+	for _, label := range []string{"Wind", "Humidity"} {
+		found := false
+		for _, line := range strings.Split(view, "\n") {
+			fields := strings.Fields(line)
+			found = found || len(fields) > 1 && fields[0] == label && fields[len(fields)-1] == missingValue
+		}
+		if !found {
+			t.Errorf("expected %s to be missing in the forecast view, got:\n%s", label, view)
+		}
+	}
+
+	if strings.Contains(view, "Chance of rain") {
+		t.Errorf("absent precipitation shouldn't be rendered, got:\n%s", view)
+	}
+}
+
+func TestFeelsLikeFallback(t *testing.T) {
+	m := model{forecastResolution: threeHourlyResolution}
+
+	var f data.Forecast
+	f.WindSpeed = "20"
+	f.Hourly = data.Hourly{Temperature: "0", Humidity: "80"}
+
+	if got := getForecastData(m, f).FeelsLikeTemp; got != "-7" {
+		t.Errorf("expected a wind chill of -7 in place of the missing value, got %q", got)
+	}
+
+	f.Hourly.FeelsLikeTemp = "-3"
+	if got := getForecastData(m, f).FeelsLikeTemp; got != "-3" {
+		t.Errorf("expected the API's feels like value to be kept, got %q", got)
+	}
+
+	f.Hourly.FeelsLikeTemp = ""
+	f.Hourly.Humidity = ""
+	if got := getForecastData(m, f).FeelsLikeTemp; got != "" {
+		t.Errorf("expected no estimate without humidity, got %q", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"extremes", []int{0, 7}, "▁█"},
+		{"scaled", []int{10, 20, 30}, "▁▅█"},
+		{"flat", []int{5, 5, 5}, "▁▁▁"},
+		{"negatives", []int{-4, 0, 3}, "▁▅█"},
+		{"single", []int{-2}, "▁"},
+	}
+
+	for _, test := range tests {
+		if got := sparkline(test.values); got != test.want {
+			t.Errorf("%s: sparkline(%v) = %q, want %q", test.name, test.values, got, test.want)
+		}
+	}
+}
+
+func TestSearchHistory(t *testing.T) {
+	m := model{table: setupTable(nil, 0), textInput: setupTextInput()}
+	for _, query := range []string{"leeds", "heathrow", "leeds", "  "} {
+		m = rememberSearch(m, query)
+	}
+
+	if len(m.searchHistory) != 2 || m.searchHistory[0] != "leeds" || m.searchHistory[1] != "heathrow" {
+		t.Fatalf("unexpected history %q", m.searchHistory)
+	}
+
+	press := func(key tea.KeyType) {
+		next, _ := updateSearch(tea.KeyMsg{Type: key}, m)
+		m = next.(model)
+	}
+
+	for _, want := range []string{"leeds", "heathrow", "heathrow"} {
+		press(tea.KeyUp)
+		if got := m.textInput.Value(); got != want {
+			t.Errorf("up: got %q, want %q", got, want)
+		}
+	}
+
+	for _, want := range []string{"leeds", ""} {
+		press(tea.KeyDown)
+		if got := m.textInput.Value(); got != want {
+			t.Errorf("down: got %q, want %q", got, want)
+		}
+	}
+
+	// typing stops the recall so the arrows are left alone
+	m.textInput.SetValue("york")
+	press(tea.KeyBackspace)
+	press(tea.KeyUp)
+	if got := m.textInput.Value(); got != "yor" {
+		t.Errorf("arrows shouldn't recall over a typed query, got %q", got)
+	}
+}
+
+func TestTypingTableShortcuts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// each of the table's shortcuts typed into the search still narrows it
+	for _, query := range []string{"eo", "es", "eh", "eS", "eF", "eD"} {
+		m := startedModel(defaultConfig(), data.Offline{})
+		for _, r := range query {
+			next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			m = next.(model)
+		}
+
+		if m.textInput.Value() != query || m.settingsChosen || m.dashboardChosen || len(m.favourites) > 0 {
+			t.Errorf("%s: expected only the search to change, got %q", query, m.textInput.Value())
+		}
+
+		want := filterTable(m).table.Rows()
+		if !reflect.DeepEqual(m.table.Rows(), want) {
+			t.Errorf("%s: expected the %d rows matching the search, got %d", query, len(want), len(m.table.Rows()))
+		}
+	}
+}
+
+func TestClearSearch(t *testing.T) {
+	for _, key := range []tea.KeyType{tea.KeyCtrlU, tea.KeyEsc} {
+		m := startedModel(defaultConfig(), data.Offline{})
+		m.sortColumn, m.sortDescending = 1, true
+
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("leeds")})
+		m = next.(model)
+		if len(m.table.Rows()) >= len(m.allRows) {
+			t.Fatalf("expected the search to narrow the table, got %d rows", len(m.table.Rows()))
+		}
+
+		// clears all of it, not just what's before the cursor
+		m.textInput.SetCursor(2)
+		next, _ = m.Update(tea.KeyMsg{Type: key})
+		m = next.(model)
+
+		if m.textInput.Value() != "" || !m.textInput.Focused() {
+			t.Errorf("%s: expected an empty, focused input, got %q", key, m.textInput.Value())
+		}
+
+		if len(m.table.Rows()) != len(m.allRows) {
+			t.Errorf("%s: expected all %d rows, got %d", key, len(m.allRows), len(m.table.Rows()))
+		}
+
+		want := sortRows(m.allRows, nameColumn, false)
+		if m.sortColumn != nameColumn || m.sortDescending || !reflect.DeepEqual(m.table.Rows()[0], want[0]) {
+			t.Errorf("%s: expected the table sorted by name, got %q first", key, m.table.Rows()[0])
+		}
+	}
+}
+
+func TestChooseSiteId(t *testing.T) {
+	m := startedModel(defaultConfig(), data.Offline{})
+
+	enter := func(input string) model {
+		m.textInput.SetValue(input)
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		return next.(model)
+	}
+
+	for input, want := range map[string]string{
+		"id:leeds": "Site ids are numbers",
+		"id:":      "Site ids are numbers",
+		"id:12345": "There's no site with the id 12345",
+	} {
+		if got := enter(input); got.locationChosen || !strings.HasPrefix(got.notice, want) {
+			t.Errorf("%q: expected %q, got %q", input, want, got.notice)
+		}
+	}
+
+	got := enter("  ID: 310002")
+	if !got.locationChosen || got.locationId != "310002" {
+		t.Fatal("expected the site to be opened straight away")
+	}
+	if len(got.searchHistory) == 0 || got.searchHistory[0] != "ID: 310002" {
+		t.Errorf("expected the id to be remembered, got %q", got.searchHistory)
+	}
+
+	// the search input lets go of the keys once the site is open
+	defer applyTheme(themes[0])
+	if next, _ := got.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")}); next.(model).themeIndex != 1 {
+		t.Error("expected t to change the theme on the opened site")
+	}
+}
+
+func TestDescribeVisibility(t *testing.T) {
+	tests := []struct {
+		visibility string
+		want       string
+	}{
+		{"VP", "Very poor (<1km)"},
+		{"MO", "Moderate (4-10km)"},
+		{"EX", "Excellent (>40km)"},
+		{"UN", "Unknown"},
+		{"", "Unknown"},
+		{"0", "Very poor (<1km)"},
+		{"999", "Very poor (<1km)"},
+		{"1000", "Poor (1-4km)"},
+		{"25000", "Very good (20-40km)"},
+		{"75000", "Excellent (>40km)"},
+		{"-5", "Unknown"},
+	}
+
+	for _, test := range tests {
+		if got := describeVisibility(test.visibility); got != test.want {
+			t.Errorf("describeVisibility(%q) = %q, want %q", test.visibility, got, test.want)
+		}
+	}
+}
+
+func TestCompactTable(t *testing.T) {
+	full := Rows{{"Leeds", "310002", "yh"}, {"Heathrow", "3772", "se"}}
+	m := model{list: setupList(), table: setupTable(full, 0), tableRows: full, sortColumn: regionColumn}
+
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 40, Height: 20})
+	m = next.(model)
+
+	columns := tableColumns(m.sortColumn, false, m.width)
+	if len(columns) != 2 {
+		t.Fatalf("expected the region column to be dropped, got %v", columns)
+	}
+
+	width := 0
+	for _, column := range columns {
+		width += column.Width + 2
+	}
+	if width > 40 {
+		t.Errorf("compact columns are %d wide, more than the terminal", width)
+	}
+
+	if m.sortColumn != nameColumn {
+		t.Errorf("expected sorting to fall back to the name column, got %d", m.sortColumn)
+	}
+
+	if row := m.table.Rows()[0]; len(row) != 2 || row[nameColumn] != "Heathrow" {
+		t.Errorf("unexpected compact row %v", row)
+	}
+
+	// widening the terminal brings the region back
+	next, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 20})
+	m = next.(model)
+
+	if row := m.table.Rows()[0]; len(row) != 3 || row[regionColumn] != "se" {
+		t.Errorf("unexpected full row %v", row)
+	}
+
+	if view := searchView(m); !strings.Contains(view, "Region") {
+		t.Errorf("expected the region column when wide, got:\n%s", view)
+	}
+}
+
+func TestResizeReflow(t *testing.T) {
+	rows := Rows{{"Leeds", "310002", "yh"}, {"Heathrow", "3772", "se"}}
+	m := model{list: setupList(), table: setupTable(rows, 0), tableRows: rows, textInput: setupTextInput()}
+
+	resize := func(width, height int) {
+		next, _ := m.Update(tea.WindowSizeMsg{Width: width, Height: height})
+		m = next.(model)
+	}
+
+	resize(100, 30)
+	if m.width != 100 || m.height != 30 {
+		t.Fatalf("expected 100x30 to be stored, got %dx%d", m.width, m.height)
+	}
+
+	wide := lipgloss.Width(m.table.View())
+	if m.table.Height() != 30-searchChrome {
+		t.Errorf("expected the table to fill the height, got %d rows", m.table.Height())
+	}
+
+	resize(75, 20)
+	narrow := lipgloss.Width(m.table.View())
+	if narrow >= wide {
+		t.Errorf("expected the table to narrow from %d, got %d", wide, narrow)
+	}
+
+	if view := searchView(m); lipgloss.Width(view) > 75 {
+		t.Errorf("search view is %d wide, more than the terminal:\n%s", lipgloss.Width(view), view)
+	}
+
+	if m.table.Height() != 20-searchChrome {
+		t.Errorf("expected the table to shrink with the height, got %d rows", m.table.Height())
+	}
+
+	// long lines of text are wrapped rather than running off screen
+	m.err = errors.New(strings.Repeat("a very long error message ", 10))
+	for _, line := range strings.Split(errorView(m), "\n") {
+		if lipgloss.Width(line) > 75 {
+			t.Errorf("error view line is %d wide: %q", lipgloss.Width(line), line)
+		}
+	}
+}
+
+func TestRenderGusts(t *testing.T) {
+	tests := []struct {
+		gust  string
+		unit  windUnit
+		want  string
+		windy bool
+	}{
+		{"20", mphUnit, "up to 20mph", false},
+		{"20", kphUnit, "up to 32km/h", false},
+		{"45", mphUnit, "up to 45mph", true},
+		{missingValue, mphUnit, "not available", false},
+		{"", mphUnit, "not available", false},
+	}
+
+	for _, test := range tests {
+		fd := forecastData{GustSpeed: test.gust}
+		if got := renderGusts(fd, test.unit); !strings.Contains(got, test.want) {
+			t.Errorf("renderGusts(%q) = %q, want it to contain %q", test.gust, got, test.want)
+		}
+
+		if isWindy(fd) != test.windy {
+			t.Errorf("isWindy(%q) = %v, want %v", test.gust, !test.windy, test.windy)
+		}
+	}
+}
+
+func TestBeaufort(t *testing.T) {
+	// the last speed of each force and the first of the next
+	tests := []struct {
+		mph   int
+		force int
+	}{
+		{0, 0}, {1, 1}, {3, 1}, {4, 2}, {7, 2}, {8, 3}, {12, 3}, {13, 4},
+		{18, 4}, {19, 5}, {24, 5}, {25, 6}, {31, 6}, {32, 7}, {38, 7}, {39, 8},
+		{46, 8}, {47, 9}, {54, 9}, {55, 10}, {63, 10}, {64, 11}, {72, 11}, {73, 12},
+		{120, 12},
+	}
+
+	for _, test := range tests {
+		if force, _ := beaufort(test.mph); force != test.force {
+			t.Errorf("beaufort(%d) = force %d, want %d", test.mph, force, test.force)
+		}
+	}
+
+	if _, description := beaufort(20); description != "Fresh breeze" {
+		t.Errorf("expected 20mph to be a fresh breeze, got %q", description)
+	}
+}
+
+func TestRenderWind(t *testing.T) {
+	tests := []struct {
+		speed string
+		want  string
+		gale  bool
+	}{
+		{"20", "20mph (Force 5 – Fresh breeze)", false},
+		{"40", "40mph (Force 8 – Gale)", true},
+		{missingValue, missingValue, false},
+	}
+
+	for _, test := range tests {
+		got := renderWind(forecastData{WindSpeed: test.speed}, mphUnit)
+		if !strings.Contains(got, test.want) {
+			t.Errorf("renderWind(%q) = %q, want it to contain %q", test.speed, got, test.want)
+		}
+		if strings.Contains(got, "⚠") != test.gale {
+			t.Errorf("renderWind(%q) = %q, expected a warning: %v", test.speed, got, test.gale)
+		}
+		if test.speed == missingValue && strings.Contains(got, "Force") {
+			t.Errorf("expected no force without a speed, got %q", got)
+		}
+	}
+
+	if got := renderWind(forecastData{WindSpeed: "20", WindDirection: "WNW"}, mphUnit); !strings.HasPrefix(got, "→ WNW 20mph") {
+		t.Errorf("expected the direction before the speed, got %q", got)
+	}
+}
+
+func TestGustiness(t *testing.T) {
+	tests := []struct {
+		wind, gust int
+		want       string
+	}{
+		{15, 15, "steady"},
+		{15, 24, "steady"},
+		{15, 25, "gusty"},
+		{15, 34, "gusty"},
+		{15, 35, "squally"},
+		// gusts reported below the wind aren't anything to worry about
+		{20, 10, "steady"},
+	}
+
+	for _, test := range tests {
+		if got := gustiness(test.wind, test.gust); got != test.want {
+			t.Errorf("gustiness(%d, %d) = %q, want %q", test.wind, test.gust, got, test.want)
+		}
+	}
+
+	if got := renderGustiness(forecastData{WindSpeed: "15", GustSpeed: "32"}, mphUnit); !strings.Contains(got, "S 15mph → G 32mph (gusty)") {
+		t.Errorf("unexpected gustiness %q", got)
+	}
+
+	for _, fd := range []forecastData{{WindSpeed: "15"}, {GustSpeed: "32"}, {WindSpeed: "calm", GustSpeed: "32"}} {
+		if got := renderGustiness(fd, mphUnit); got != "" {
+			t.Errorf("expected nothing without both speeds, got %q for %+v", got, fd)
+		}
+	}
+}
+
+func TestInterpretStatus(t *testing.T) {
+	tests := map[int]string{
+		403: "API key was rejected (HTTP 403)",
+		404: "Location not found",
+		429: "Too many requests",
+		500: "having problems (HTTP 500)",
+		503: "having problems (HTTP 503)",
+		400: "Unexpected response from the Met Office API (HTTP 400)",
+	}
+
+	for code, want := range tests {
+		if got := interpretStatus(code); !strings.Contains(got, want) {
+			t.Errorf("interpretStatus(%d) = %q, want it to contain %q", code, got, want)
+		}
+	}
+
+	err := fmt.Errorf("could not fetch site data: %w", &data.StatusError{Code: 403})
+	if got := describeError(err); !strings.Contains(got, "MET_OFFICE_API_KEY") {
+		t.Errorf("expected a wrapped 403 to be explained, got %q", got)
+	}
+}
+
+func TestChangeDays(t *testing.T) {
+	m := model{list: setupList(), forecastResolution: dailyResolution, maxDays: 2}
+	for _, date := range []string{"2024-01-10Z", "2024-01-11Z", "2024-01-12Z"} {
+		m.siteData.Site.Info.Location.Periods = append(m.siteData.Site.Info.Location.Periods,
+			data.Period{Date: date, Forecasts: []data.Forecast{{Time: "Day"}, {Time: "Night"}}})
+	}
+
+	if items := getForecastListItems(m); len(items) != 4 {
+		t.Fatalf("expected 2 days of items, got %d", len(items))
+	}
+
+	steps := []struct {
+		more    bool
+		maxDays int
+		items   int
+	}{
+		{false, 1, 2},
+		{false, 1, 2},
+		{true, 2, 4},
+		{true, 0, 6},
+		{true, 0, 6},
+	}
+
+	for i, step := range steps {
+		m, _ = changeDays(m, step.more)
+		if m.maxDays != step.maxDays || len(m.list.Items()) != step.items {
+			t.Errorf("step %d: got %d days and %d items, want %d and %d", i, m.maxDays, len(m.list.Items()), step.maxDays, step.items)
+		}
+	}
+}
+
+func TestExtractRows(t *testing.T) {
+	body := []byte(`{"locations": {"location": [
+		{"id": "2", "name": "Leeds", "region": "yh", "latitude": "53.8", "longitude": "-1.5"},
+		{"id": "1", "name": "Aberdeen", "region": "gr"}
+	]}}`)
+
+	rows, names, coords, err := extractRows(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 2 || rows[0][nameColumn] != "Aberdeen" || len(names) != 2 || len(coords) != 1 {
+		t.Errorf("unexpected sitelist %v %v %v", rows, names, coords)
+	}
+
+	for _, body := range []string{`<html>`, `{}`, `{"locations": {"location": []}}`} {
+		if _, _, _, err := extractRows([]byte(body)); err == nil {
+			t.Errorf("expected an error for the sitelist %s", body)
+		}
+	}
+}
+
+func TestDisambiguateNames(t *testing.T) {
+	body := []byte(`{"locations": {"location": [
+		{"id": "1", "name": "Newport", "region": "wl"},
+		{"id": "2", "name": "Leeds", "region": "yh"},
+		{"id": "3", "name": "Newport", "region": "se"},
+		{"id": "4", "name": "Richmond", "region": "yh"},
+		{"id": "4", "name": "Richmond", "region": "yh"},
+		{"id": "6", "name": "Ashford", "region": "se"},
+		{"id": "7", "name": "Ashford", "region": "se"}
+	]}}`)
+
+	rows, names, _, err := extractRows(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// only the names shared by different sites get their region, and
+	// their id too if that's shared as well
+	want := map[string]string{
+		"1": "Newport (Wales)",
+		"2": "Leeds",
+		"3": "Newport (London & South East England)",
+		"4": "Richmond",
+		"6": "Ashford (London & South East England, 6)",
+		"7": "Ashford (London & South East England, 7)",
+	}
+	for _, row := range rows {
+		if row[nameColumn] != want[row[idColumn]] {
+			t.Errorf("expected site %s to be %q, got %q", row[idColumn], want[row[idColumn]], row[nameColumn])
+		}
+	}
+	if !slices.Contains(names, "Newport (Wales)") || slices.Contains(names, "Newport") {
+		t.Errorf("expected the placenames to match the rows, got %v", names)
+	}
+
+	// an observation site sharing a name is told apart once merged
+	merged := mergeSitelists(rows, Rows{{"Leeds", "5", "ne"}, {"Newport", "1", "wl"}})
+	for _, row := range merged {
+		if row[idColumn] == "2" && row[nameColumn] != "Leeds (Yorkshire & Humber)" {
+			t.Errorf("expected the forecast site to get its region, got %q", row[nameColumn])
+		}
+		if row[idColumn] == "1" && row[nameColumn] != "Newport (Wales)" {
+			t.Errorf("expected the name to be suffixed once, got %q", row[nameColumn])
+		}
+		if row[idColumn] == "6" && (row[nameColumn] != want["6"] || placename(row) != "Ashford") {
+			t.Errorf("expected the id suffixed once, got %q", row[nameColumn])
+		}
+	}
+
+	// the chosen site's title confirms its region
+	m := model{allRows: rows, locationId: "3"}
+	m.siteData.Site.Info.Location.Name = "NEWPORT"
+	m.siteData.Site.Info.Location.Country = "ENGLAND"
+	if title := locationTitle(m); title != "NEWPORT (London & South East England), ENGLAND" {
+		t.Errorf("expected the region in the title, got %q", title)
+	}
+
+	m.locationId = "7"
+	m.siteData.Site.Info.Location.Name = "ASHFORD"
+	if title := locationTitle(m); title != "ASHFORD (London & South East England, 7), ENGLAND" {
+		t.Errorf("expected the region and id in the title, got %q", title)
+	}
+
+	m.locationId = "2"
+	m.siteData.Site.Info.Location.Name = "LEEDS"
+	if title := locationTitle(m); title != "LEEDS, ENGLAND" {
+		t.Errorf("expected no region for a name only one site has, got %q", title)
+	}
+}
+
+func TestMergeSitelists(t *testing.T) {
+	forecast := Rows{{"Leeds", "2", "yh"}, {"Aberdeen", "1", "gr"}, {"Leeds", "2", "yh"}}
+	obs := Rows{{"Leeds", "2", "yh"}, {"Lerwick", "3", "os"}, {"Lerwick", "3", "os"}}
+
+	rows := mergeSitelists(forecast, obs)
+
+	want := Rows{
+		{"Aberdeen", "1", "gr", forecastsOnly},
+		{"Leeds", "2", "yh", withObservations},
+		{"Lerwick", "3", "os", observationsOnly},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("expected %v, got %v", want, rows)
+	}
+
+	// the sitelists it was given are left alone
+	if len(forecast[0]) != 3 || len(obs[0]) != 3 {
+		t.Errorf("expected the original rows to be unchanged, got %v and %v", forecast, obs)
+	}
+
+	for id, kinds := range map[string][2]bool{"1": {true, false}, "2": {true, true}, "3": {false, true}, "4": {true, false}} {
+		if forecasts, observations := siteDataKinds(rows, id); forecasts != kinds[0] || observations != kinds[1] {
+			t.Errorf("site %s: expected %v, got %v %v", id, kinds, forecasts, observations)
+		}
+	}
+}
+
+func TestObservationsOnlySite(t *testing.T) {
+	rows := mergeSitelists(Rows{{"Leeds", "310002", "yh"}}, Rows{{"Lerwick", "3005", "os"}})
+	m := model{list: setupList(), allRows: rows, observationSites: map[string]bool{"3005": true}}
+
+	m, _ = chooseLocation(m, "3005")
+	if !m.observing {
+		t.Fatal("expected a site with only observations to open on them")
+	}
+
+	m.loading = false
+	m, cmd := toggleObservations(m)
+	if cmd != nil || !m.observing || !strings.Contains(m.notice, "Only observations") {
+		t.Errorf("expected no forecasts to be offered, got %q", m.notice)
+	}
+}
+
+// update the model with the messages from a command, including each
+// of a batch, without running any commands that follow
+func runCmd(m model, cmd tea.Cmd) model {
+	if cmd == nil {
+		return m
+	}
+
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			m = runCmd(m, c)
+		}
+
+		return m
+	}
+
+	next, _ := m.Update(msg)
+
+	return next.(model)
+}
+
+// the model once its sitelist has loaded from source, as it would be
+// shortly after the program starts
+func startedModel(cfg Config, source data.Source) model {
+	m := initialModel(cfg)
+	m.fetcher = source
+	next, _ := m.Update(loadSitelist(m)())
+
+	return next.(model)
+}
+
+func TestInitialModelLoadsSitelist(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Location = "310002"
+	m := initialModel(cfg)
+	m.fetcher = data.Offline{}
+
+	if !m.loadingSites || len(m.allRows) != 0 {
+		t.Fatal("expected the sitelist to be fetched after starting")
+	}
+
+	if view := m.View(); !strings.Contains(view, "Loading locations…") {
+		t.Errorf("expected a loading splash, got:\n%s", view)
+	}
+
+	next, cmd := m.Update(loadSitelist(m)())
+	m = next.(model)
+
+	if m.loadingSites || len(m.allRows) == 0 {
+		t.Fatal("expected the sitelist to have loaded")
+	}
+
+	// the location chosen up front is opened once there are sites
+	if !m.locationChosen || m.locationId != "310002" || cmd == nil {
+		t.Error("expected the configured location to start loading")
+	}
+}
+
+func TestInitialModelWithUnknownHome(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Location = "999999"
+	m := startedModel(cfg, data.Offline{})
+
+	if m.locationChosen || m.err != nil {
+		t.Fatal("expected to stay on the search for a site that's gone")
+	}
+
+	if !strings.Contains(m.notice, "999999 is no longer available") {
+		t.Errorf("expected an explanation, got %q", m.notice)
+	}
+}
+
+func TestInitialModelWithoutSitelist(t *testing.T) {
+	m := startedModel(defaultConfig(), failingSource{})
+	if m.err == nil {
+		t.Fatal("expected a startup error")
+	}
+
+	if view := m.View(); !strings.Contains(view, "could not load the list of sites") || !strings.Contains(view, "ctrl+c") {
+		t.Errorf("unexpected error view:\n%s", view)
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if next.(model).err == nil {
+		t.Error("esc shouldn't leave the error for an empty search")
+	}
+}
+
+func TestInitialModelWithInvalidSettings(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Theme = "neon"
+
+	m := initialModel(cfg)
+	if m.err == nil || m.loadingSites {
+		t.Fatal("expected a settings error instead of loading")
+	}
+
+	if view := m.View(); !strings.Contains(view, "invalid settings") {
+		t.Errorf("unexpected error view:\n%s", view)
+	}
+}
+
+func TestExtractRowsTwice(t *testing.T) {
+	body, err := data.Offline{}.Get(context.Background(), makeUrl("val/wxfcs/all/json/sitelist"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, firstNames, _, err := extractRows(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing carries over from one run to the next
+	second, secondNames, _, err := extractRows(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(first) == 0 || len(second) != len(first) || len(secondNames) != len(firstNames) {
+		t.Errorf("got %d then %d rows, %d then %d names", len(first), len(second), len(firstNames), len(secondNames))
+	}
+}
+
+func TestRefreshNow(t *testing.T) {
+	m := startedModel(defaultConfig(), data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	m = next.(model)
+
+	m = runCmd(chooseLocation(m, "310002"))
+
+	m.list.Select(3)
+	m, _ = openForecast(m)
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	m = next.(model)
+	if m.notice != "Refreshing…" || cmd == nil {
+		t.Fatal("expected a refresh to start")
+	}
+
+	refreshed := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	defer func(c func() time.Time) { clock = c }(clock)
+	clock = func() time.Time { return refreshed }
+	m = runCmd(m, cmd)
+
+	if m.notice != "Refreshed" || !m.lastUpdated.Equal(refreshed) {
+		t.Errorf("expected a confirmation at %v, got notice %q at %v", refreshed, m.notice, m.lastUpdated)
+	}
+
+	if m.list.Index() != 3 || !m.forecastChosen {
+		t.Errorf("expected the selected forecast to be kept, got index %d", m.list.Index())
+	}
+
+	if !strings.Contains(forecastView(m), "Refreshed") {
+		t.Error("expected the confirmation on the forecast view")
+	}
+}
+
+func TestThrottledLoading(t *testing.T) {
+	defer func(limiter *data.Limiter) { data.DefaultClient.Limiter = limiter }(data.DefaultClient.Limiter)
+
+	m := model{loading: true, fetcher: data.DefaultClient}
+
+	data.DefaultClient.Limiter = data.NewLimiter(1)
+	if view := locationView(m); strings.Contains(view, "rate limited") {
+		t.Errorf("expected no note before any requests, got:\n%s", view)
+	}
+
+	// the first request takes the only slot for a minute, but wasn't
+	// held up itself
+	if err := data.DefaultClient.Limiter.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if view := locationView(m); strings.Contains(view, "rate limited") {
+		t.Errorf("expected no note after a single request, got:\n%s", view)
+	}
+
+	// the next has to wait for it
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- data.DefaultClient.Limiter.Wait(ctx) }()
+	for deadline := time.Now().Add(time.Second); !throttled(m) && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if view := locationView(m); !strings.Contains(view, "rate limited, waiting…") {
+		t.Errorf("expected a note that the request is waiting, got:\n%s", view)
+	}
+
+	cancel()
+	<-done
+	if view := locationView(m); strings.Contains(view, "rate limited") {
+		t.Errorf("expected the note to go once nothing is waiting, got:\n%s", view)
+	}
+}
+
+func TestExpandedList(t *testing.T) {
+	m := startedModel(Config{Resolution: "3hourly", TemperatureUnit: "C", WindUnit: "mph", Theme: "dark"}, data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 64, Height: 30})
+	m = next.(model)
+	m = runCmd(chooseLocation(m, "310002"))
+
+	compact := m.list.Paginator.PerPage
+	if strings.Contains(locationView(m), "Feels like") {
+		t.Fatal("expected the details to be hidden to begin with")
+	}
+
+	press := func(key string) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = next.(model)
+	}
+
+	press("e")
+	view := locationView(m)
+	for _, want := range []string{"Feels like", "chance of rain", "UV", "Gusts"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the expanded list, got:\n%s", want, view)
+		}
+	}
+	if m.list.Paginator.PerPage >= compact {
+		t.Errorf("expected fewer of the taller items to a page, got %d then %d", compact, m.list.Paginator.PerPage)
+	}
+
+	// scrolling past the first page still shows the selection
+	for i := 0; i < m.list.Paginator.PerPage; i++ {
+		next, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = next.(model)
+	}
+	if m.list.Paginator.Page != 1 || !strings.Contains(locationView(m), m.list.SelectedItem().(forecastItem).Title()) {
+		t.Errorf("expected the second page with the selection on it, got page %d", m.list.Paginator.Page)
+	}
+
+	press("e")
+	if strings.Contains(locationView(m), "Feels like") || m.list.Paginator.PerPage != compact {
+		t.Error("expected e to collapse the details again")
+	}
+}
+
+func TestComfortLabel(t *testing.T) {
+	tests := []struct {
+		temp, humidity int
+		want           string
+	}{
+		{20, 29, "dry"},
+		{-5, 10, "dry"},
+		{20, 30, "comfortable"},
+		{17, 95, "comfortable"},
+		{18, 69, "comfortable"},
+		{18, 70, "muggy"},
+		{28, 90, "muggy"},
+	}
+
+	for _, test := range tests {
+		if got := comfortLabel(test.temp, test.humidity); got != test.want {
+			t.Errorf("comfortLabel(%d, %d) = %q, want %q", test.temp, test.humidity, got, test.want)
+		}
+	}
+
+	if got := renderHumidity(forecastData{Temperature: "20", Humidity: "75"}, "%", 10); !strings.HasSuffix(got, "75% (muggy)") {
+		t.Errorf("expected a comfort label, got %q", got)
+	}
+
+	if got := renderHumidity(forecastData{Temperature: missingValue, Humidity: "75"}, "%", 10); strings.Contains(got, "(") {
+		t.Errorf("expected no comfort label without a temperature, got %q", got)
+	}
+}
+
+func TestFormatForecastTime(t *testing.T) {
+	tests := []struct {
+		minutes    int
+		twelveHour string
+		twentyFour string
+	}{
+		{0, "12:00am", "00:00"},
+		{180, "3:00am", "03:00"},
+		{720, "12:00pm", "12:00"},
+		{1260, "9:00pm", "21:00"},
+	}
+
+	for _, test := range tests {
+		if got := formatForecastTime(test.minutes, true); got != test.twelveHour {
+			t.Errorf("%d minutes: got %q, want %q", test.minutes, got, test.twelveHour)
+		}
+		if got := formatForecastTime(test.minutes, false); got != test.twentyFour {
+			t.Errorf("%d minutes: got %q, want %q", test.minutes, got, test.twentyFour)
+		}
+	}
+}
+
+func TestToggleClock(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local = time.UTC
+
+	cfg := defaultConfig()
+	cfg.Resolution = string(threeHourlyResolution)
+	m := runCmd(chooseLocation(startedModel(cfg, data.Offline{}), "310002"))
+	m.textInput.Blur()
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("H")})
+	m = next.(model)
+
+	if title := m.list.Items()[0].(forecastItem).Title(); !strings.HasSuffix(title, "(9:00am)") {
+		t.Errorf("expected a 12 hour time in %q", title)
+	}
+
+	if cfg, err := loadConfig(); err != nil || !cfg.TwelveHour {
+		t.Errorf("expected the time format to be saved, got %+v and %v", cfg, err)
+	}
+}
+
+func TestParsePeriodDate(t *testing.T) {
+	want := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	for _, s := range []string{"2024-01-10Z", "2024-01-10", " 2024-01-10Z ", "2024-01-10T00:00:00Z"} {
+		got, err := parsePeriodDate(s)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("parsePeriodDate(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+
+	for _, s := range []string{"", "10/01/2024", "Day"} {
+		if _, err := parsePeriodDate(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func TestForecastListLocalTimes(t *testing.T) {
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local = time.FixedZone("UTC-5", -5*60*60)
+
+	m := model{list: setupList(), forecastResolution: threeHourlyResolution}
+	m.siteData.Site.Info.Location.Periods = []data.Period{
+		{Date: "garbled", Forecasts: []data.Forecast{{Time: "0"}}},
+		{Date: "2024-01-10Z", Forecasts: []data.Forecast{{Time: "180"}, {Time: "900"}}},
+	}
+
+	items := getForecastListItems(m)
+	if len(items) != 2 {
+		t.Fatalf("expected the garbled period to be skipped, got %d items", len(items))
+	}
+
+	// 03:00 UTC is still the evening before five hours behind
+	for i, want := range []string{"Tue, 09 Jan 2024 (22:00)", "Wed, 10 Jan 2024 (10:00)"} {
+		if got := items[i].(forecastItem).Title(); got != want {
+			t.Errorf("item %d: got %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestQuitKey(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	quits := func(m model) bool {
+		_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+		if cmd == nil {
+			return false
+		}
+		_, ok := cmd().(tea.QuitMsg)
+		return ok
+	}
+
+	m := startedModel(defaultConfig(), data.Offline{})
+	if quits(m) {
+		t.Error("expected q to be typed into the search input")
+	}
+
+	m = focusTable(m)
+	if !quits(m) {
+		t.Error("expected q to quit from the search table")
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = runCmd(next.(model), cmd)
+	if !m.locationChosen || !quits(m) {
+		t.Error("expected q to quit from a location's forecasts")
+	}
+
+	// esc steps back rather than quitting
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if m = next.(model); m.locationChosen || cmd != nil {
+		t.Error("expected esc to go back to the search")
+	}
+}
+
+func TestDayHighLow(t *testing.T) {
+	day := data.Forecast{Time: "Day", Day: data.Day{Temperature: "12"}}
+	night := data.Forecast{Time: "Night", Night: data.Night{Temperature: "4"}}
+
+	tests := []struct {
+		forecasts []data.Forecast
+		want      string
+	}{
+		{[]data.Forecast{day, night}, "High 12°C / Low 4°C"},
+		// late in the evening only the night is left
+		{[]data.Forecast{night}, "Low 4°C"},
+		{[]data.Forecast{day}, "High 12°C"},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		high, low := dayHighLow(data.Period{Forecasts: tt.forecasts})
+		if got := formatHighLow(high, low, celsiusUnit); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+
+	high, low := dayHighLow(data.Period{Forecasts: []data.Forecast{day, night}})
+	if got := formatHighLow(high, low, fahrenheitUnit); got != "High 54°F / Low 39°F" {
+		t.Errorf("expected the high and low in fahrenheit, got %q", got)
+	}
+}
+
+// an example of standing in for the network with canned responses, from
+// choosing a search result through to one of its forecasts
+func TestSelectionWithStubSource(t *testing.T) {
+	source := data.SourceFunc(func(ctx context.Context, url string) ([]byte, error) {
+		switch {
+		case strings.Contains(url, "wxfcs/all/json/sitelist"):
+			return []byte(`{"Locations": {"Location": [{"id": "99", "name": "Stubton", "region": "yh"}]}}`), nil
+		case strings.Contains(url, "wxfcs/all/json/99?"):
+			return []byte(`{"SiteRep": {"DV": {"dataDate": "2024-01-10T09:00:00Z", "Location": {"i": "99", "name": "STUBTON", "Period": [
+				{"type": "Day", "value": "2024-01-10Z", "Rep": [{"$": "Day", "W": "1", "Dm": "14", "S": "5", "D": "N", "V": "GO"}]}
+			]}}}}`), nil
+		}
+
+		return nil, errors.New("no stub for " + url)
+	})
+
+	m := startedModel(defaultConfig(), source)
+	if len(m.allRows) != 1 {
+		t.Fatalf("expected the stub's sitelist, got %v", m.allRows)
+	}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	next, cmd := next.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = runCmd(next.(model), cmd)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+
+	if !m.forecastChosen || m.locationId != "99" {
+		t.Fatalf("expected the stub site's forecast to be open, got error %v", m.err)
+	}
+	if view := m.View(); !strings.Contains(view, "STUBTON") || !strings.Contains(view, "Sunny day") || !strings.Contains(view, "14°C") {
+		t.Errorf("expected the stub forecast to be shown, got:\n%s", view)
+	}
+}
+
+func TestNowSlot(t *testing.T) {
+	defer func(c func() time.Time) { clock = c }(clock)
+
+	cfg := defaultConfig()
+	cfg.Resolution = string(threeHourlyResolution)
+
+	open := func(now time.Time) model {
+		clock = func() time.Time { return now }
+		return runCmd(chooseLocation(startedModel(cfg, data.Offline{}), "310002"))
+	}
+
+	// the 12:00 slot is under way
+	m := open(time.Date(2024, 1, 10, 13, 0, 0, 0, time.UTC).Local())
+	item := m.list.SelectedItem().(forecastItem)
+	if !item.now || !strings.HasSuffix(item.Title(), "· now") || m.list.Index() != 1 {
+		t.Errorf("expected the list to start on the 12:00 slot, got %q at %d", item.Title(), m.list.Index())
+	}
+
+	// before the first slot the next one to come is now
+	m = open(time.Date(2024, 1, 10, 5, 0, 0, 0, time.UTC).Local())
+	if item := m.list.SelectedItem().(forecastItem); !item.now || m.list.Index() != 0 {
+		t.Errorf("expected the first slot to be now, got %q", item.Title())
+	}
+
+	// once every slot has passed there's no now to mark
+	m = open(time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC))
+	for _, item := range m.list.Items() {
+		if item.(forecastItem).now {
+			t.Errorf("expected no slot to be now, got %q", item.(forecastItem).Title())
+		}
+	}
+}
+
+func TestEnterWithNoMatches(t *testing.T) {
+	m := startedModel(defaultConfig(), data.Offline{})
+	m.textInput.SetValue("zzzzzz")
+	m = filterTable(m)
+	if len(m.table.Rows()) != 0 || m.table.SelectedRow() != nil {
+		t.Fatalf("expected the table to be emptied, got %v", m.table.Rows())
+	}
+
+	press := func() {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		m = next.(model)
+	}
+
+	// the input keeps focus so the search can be changed
+	press()
+	if m.table.Focused() || m.notice != noMatchesNotice {
+		t.Errorf("expected to stay in the input with a notice, got %q", m.notice)
+	}
+
+	// and an empty table can't open anything
+	m = focusTable(m)
+	press()
+	if m.locationChosen || m.notice != noMatchesNotice {
+		t.Errorf("expected nothing to be chosen, got %q", m.notice)
+	}
+}
+
+func TestRankMatchesLimits(t *testing.T) {
+	names := []string{
+		"Lerwick",
+		"London",
+		"Leeds",
+		"Lincoln",
+		"London Heathrow Airport",
+		"Llandudno",
+		"Loch Glascarnoch Saws",
+		"Liverpool John Lennon Airport",
+	}
+	var rows Rows
+	for i, name := range names {
+		rows = append(rows, table.Row{name, strconv.Itoa(i), "", ""})
+	}
+
+	ranked := func(query string, maxDistance, limit int) []string {
+		var got []string
+		for _, row := range rankMatches(query, rows, names, maxDistance, limit) {
+			got = append(got, row[nameColumn])
+		}
+		return got
+	}
+
+	if got := ranked("l", 0, 0); len(got) != len(names) {
+		t.Fatalf("expected every name uncapped, got %v", got)
+	}
+
+	// the closest matches are kept
+	if got := ranked("l", 0, 2); !slices.Equal(got, []string{"Leeds", "London"}) {
+		t.Errorf("expected the 2 closest matches, got %v", got)
+	}
+
+	// "London Heathrow Airport" is 21 from "lon"
+	if got := ranked("lon", 20, 0); slices.Contains(got, "London Heathrow Airport") || !slices.Contains(got, "London") {
+		t.Errorf("expected the distant match to be dropped, got %v", got)
+	}
+	if got := ranked("lon", 21, 0); !slices.Contains(got, "London Heathrow Airport") {
+		t.Errorf("expected a match at the threshold to be kept, got %v", got)
+	}
+
+	m := startedModel(defaultConfig(), data.Offline{})
+	if m.searchResults != defaultSearchResults || m.searchDistance != defaultSearchDistance {
+		t.Errorf("expected the default limits, got %d and %d", m.searchResults, m.searchDistance)
+	}
+
+	m.searchResults = 2
+	m.textInput.SetValue("a")
+	if m = filterTable(m); len(m.tableRows) != 2 {
+		t.Errorf("expected the search to list 2 matches, got %d", len(m.tableRows))
+	}
+}
+
+func TestRainTrend(t *testing.T) {
+	slot := func(minutes, rain string) data.Forecast {
+		return data.Forecast{Time: minutes, Hourly: data.Hourly{Precipitation: rain}}
+	}
+
+	m := model{forecastResolution: threeHourlyResolution}
+	m.siteData.Site.Info.Location.Periods = []data.Period{
+		{Date: "2024-01-10Z", Forecasts: []data.Forecast{slot("0", "10"), slot("180", "40"), slot("360", "40"), slot("540", "5"), slot("720", ""), slot("900", "20")}},
+		{Date: "2024-01-11Z", Forecasts: []data.Forecast{slot("0", "50"), slot("180", "60")}},
+	}
+
+	// the day's first slot and one after an unknown chance have nothing
+	// to compare with, and the next day starts afresh
+	want := []string{"☂ 10% ·", "☂ 40% ↑", "☂ 40% →", "☂ 5% ↓", "", "☂ 20% ·", "☂ 50% ·", "☂ 60% ↑"}
+	items := getForecastListItems(m)
+	if len(items) != len(want) {
+		t.Fatalf("expected %d items, got %d", len(want), len(items))
+	}
+	for i, item := range items {
+		desc := item.(forecastItem).Description()
+		if want[i] == "" && strings.Contains(desc, "☂") || want[i] != "" && !strings.HasSuffix(desc, want[i]) {
+			t.Errorf("expected item %d to end %q, got %q", i, want[i], desc)
+		}
+	}
+
+	// daily forecasts don't show it
+	m.forecastResolution = dailyResolution
+	for _, item := range getForecastListItems(m) {
+		if strings.Contains(item.(forecastItem).Description(), "☂") {
+			t.Errorf("expected no trend in daily forecasts, got %q", item.(forecastItem).Description())
+		}
+	}
+}
+
+func TestWindTrend(t *testing.T) {
+	tests := []struct {
+		directions []string
+		want       string
+	}{
+		{[]string{"SW", "W", "WNW", "NW"}, "veering"},
+		// turning the short way round through north
+		{[]string{"NNW", "N", "NE", "ENE"}, "veering"},
+		{[]string{"E", "NE", "N", "NW"}, "backing"},
+		// a point's wobble either way is still steady
+		{[]string{"S", "SSW", "S", "SSE", "S"}, "steady"},
+		{[]string{"SW", "", "W", "NW"}, "veering"},
+		{[]string{"SW", "?"}, ""},
+		{nil, ""},
+	}
+
+	for _, test := range tests {
+		if got := windTrend(test.directions); got != test.want {
+			t.Errorf("%q: got %q, want %q", test.directions, got, test.want)
+		}
+	}
+
+	var period data.Period
+	period.Date = "2024-01-10Z"
+	for i, direction := range []string{"S", "SW", "W", "NW"} {
+		period.Forecasts = append(period.Forecasts, data.Forecast{Time: strconv.Itoa(i * 180), WindDirection: direction})
+	}
+	var siteData data.SiteData
+	siteData.Site.Info.Location.Periods = []data.Period{period}
+
+	if summaries := summariseDays(siteData); len(summaries) != 1 || summaries[0].wind != "veering" {
+		t.Errorf("expected the day summarised as veering, got %+v", summaries)
+	}
+}
+
+func TestSitelistProgress(t *testing.T) {
+	m := initialModel(defaultConfig())
+	m.fetcher = data.SourceFunc(func(ctx context.Context, url string) ([]byte, error) {
+		if progress := data.ProgressFrom(ctx); progress != nil {
+			progress(1_500_000, 6_000_000)
+		}
+		return data.Offline{}.Get(ctx, url)
+	})
+
+	if view := splashView(m); strings.Contains(view, "MB") {
+		t.Errorf("expected no progress before the download starts, got:\n%s", view)
+	}
+
+	msg := loadSitelist(m)()
+	progress, ok := msg.(sitelistProgressMsg)
+	if !ok {
+		t.Fatalf("expected the download's progress first, got %T", msg)
+	}
+
+	next, cmd := m.Update(progress)
+	m = next.(model)
+	if view := splashView(m); !strings.Contains(view, "1.5 MB of 6.0 MB (25%)") {
+		t.Errorf("expected the progress in the splash, got:\n%s", view)
+	}
+
+	// the rest of the download's messages follow until it's loaded
+	for m.loadingSites {
+		next, cmd = m.Update(cmd())
+		m = next.(model)
+	}
+	if len(m.allRows) == 0 {
+		t.Error("expected the sitelist to load after its progress")
+	}
+
+	m.sitelistRead, m.sitelistTotal = 2_500, -1
+	if got := sitelistProgress(m); got != " 2 KB" {
+		t.Errorf("expected just what's arrived without a total, got %q", got)
+	}
+}
+
+func TestForecastDebugCodes(t *testing.T) {
+	m := startedModel(defaultConfig(), data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 64, Height: 30})
+	m = runCmd(chooseLocation(next.(model), "310002"))
+	m.list.Select(2)
+	m, _ = openForecast(m)
+
+	if strings.Contains(forecastView(m), "(Dm)") {
+		t.Fatal("expected no field codes until debugging")
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	m = next.(model)
+	view := forecastView(m)
+	for _, want := range []string{"Temperature (Dm)", "Wind (D, S)", "Gustiness (S, Gn)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the forecast view, got:\n%s", want, view)
+		}
+	}
+
+	// the longer labels mustn't push the bars onto another line
+	for _, line := range strings.Split(view, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "Humidity") && !strings.Contains(line, "(comfortable)") {
+			t.Errorf("expected the humidity on one line, got:\n%s", view)
+		}
+	}
+}
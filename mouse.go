@@ -0,0 +1,157 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// neither the table nor the list know where they were drawn, so clicks
+// are placed by finding the selected entry on screen and counting lines
+// from it
+
+var ansiPattern = regexp.MustCompile("\x1b\\[[0-9;?]*[A-Za-z]")
+
+// the lines of a rendered view without any styling
+func screenLines(view string) []string {
+	return strings.Split(ansiPattern.ReplaceAllString(view, ""), "\n")
+}
+
+func findLine(lines []string, matches func(line string) bool) int {
+	for i, line := range lines {
+		if matches(line) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// whether a screen line shows a table row, names are truncated to fit
+// their column in the same way the table does
+func rowOnLine(row table.Row, columns []table.Column, line string) bool {
+	name := runewidth.Truncate(row[nameColumn], columns[nameColumn].Width, "…")
+
+	return strings.Contains(line, " "+name+" ") && strings.Contains(line, " "+row[idColumn]+" ")
+}
+
+// the table row drawn at screen line y, or -1 if there isn't one
+func clickedRow(m model, y int) int {
+	selected := m.table.SelectedRow()
+	if selected == nil {
+		return -1
+	}
+
+	lines := screenLines(searchView(m))
+	columns := tableColumns(m.sortColumn, m.sortDescending, m.width)
+	selectedLine := findLine(lines, func(line string) bool {
+		return rowOnLine(selected, columns, line)
+	})
+
+	rows := m.table.Rows()
+	row := m.table.Cursor() + y - selectedLine
+	if selectedLine < 0 || y < 0 || y >= len(lines) || row < 0 || row >= len(rows) {
+		return -1
+	}
+
+	// rows beyond the table's height aren't drawn
+	if !rowOnLine(rows[row], columns, lines[y]) {
+		return -1
+	}
+
+	return row
+}
+
+// clicking a row selects it, clicking it again opens it like enter
+func updateSearchMouse(msg tea.MouseMsg, m model) (model, tea.Cmd) {
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.table.MoveUp(1)
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.table.MoveDown(1)
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		row := clickedRow(m, msg.Y)
+		if row < 0 {
+			break
+		}
+
+		if m.table.Focused() && row == m.table.Cursor() {
+			return chooseSelectedRow(m)
+		}
+
+		m = focusTable(m)
+		m.table.SetCursor(row)
+	}
+
+	return m, nil
+}
+
+// the index of the list item drawn at screen line y, or -1 if there
+// isn't one
+func clickedItem(m model, y int) int {
+	selected, ok := m.list.SelectedItem().(forecastItem)
+	if !ok {
+		return -1
+	}
+
+	lines := screenLines(locationView(m))
+	selectedLine := findLine(lines, func(line string) bool {
+		return strings.Contains(line, selected.Title())
+	})
+	if selectedLine < 0 || y < 0 || y >= len(lines) {
+		return -1
+	}
+
+	d := newListDelegate(m.expanded)
+	step := d.Height() + d.Spacing()
+
+	offset := y - selectedLine
+	// ignore clicks on the gap between items
+	if ((offset%step)+step)%step >= d.Height() {
+		return -1
+	}
+
+	// round towards the item above for clicks on a description
+	if offset < 0 {
+		offset -= step - 1
+	}
+	index := m.list.Index() + offset/step
+
+	// only items on the current page are drawn
+	start, end := m.list.Paginator.GetSliceBounds(len(m.list.VisibleItems()))
+	if index < start || index >= end {
+		return -1
+	}
+
+	return index
+}
+
+// clicking a forecast selects it, clicking it again opens it like enter
+func updateLocationMouse(msg tea.MouseMsg, m model) (model, tea.Cmd) {
+	if m.loading {
+		return m, nil
+	}
+
+	switch {
+	case msg.Button == tea.MouseButtonWheelUp:
+		m.list.CursorUp()
+	case msg.Button == tea.MouseButtonWheelDown:
+		m.list.CursorDown()
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		index := clickedItem(m, msg.Y)
+		if index < 0 {
+			break
+		}
+
+		if index == m.list.Index() {
+			return openForecast(m)
+		}
+
+		m.list.Select(index)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jasonleelunn/forecast/internal/data"
+	"github.com/muesli/termenv"
+)
+
+func click(m model, y int) (model, tea.Cmd) {
+	next, cmd := m.Update(tea.MouseMsg{Y: y, Action: tea.MouseActionPress, Button: tea.MouseButtonLeft})
+	return next.(model), cmd
+}
+
+// the first screen line containing text
+func lineOf(view string, text string) int {
+	return findLine(screenLines(view), func(line string) bool {
+		return strings.Contains(line, text)
+	})
+}
+
+func TestClickSearchTable(t *testing.T) {
+	m := startedModel(defaultConfig(), data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	m = next.(model)
+
+	// clicking the border shouldn't do anything
+	m, _ = click(m, 0)
+	if m.table.Focused() {
+		t.Fatal("clicking outside the table shouldn't focus it")
+	}
+
+	m, _ = click(m, lineOf(searchView(m), "Leeds"))
+	if !m.table.Focused() || m.textInput.Focused() {
+		t.Fatal("clicking a row should focus the table")
+	}
+
+	if row := m.table.SelectedRow(); row[nameColumn] != "Leeds" {
+		t.Fatalf("expected Leeds to be selected, got %v", row)
+	}
+
+	m, cmd := click(m, lineOf(searchView(m), "Leeds"))
+	if !m.locationChosen || m.locationId != "310002" || cmd == nil {
+		t.Error("clicking the selected row should choose it")
+	}
+}
+
+func TestClickForecastList(t *testing.T) {
+	m := startedModel(defaultConfig(), data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 40})
+	m = next.(model)
+
+	m = runCmd(chooseLocation(m, "310002"))
+
+	// click the description of the third forecast
+	target := m.list.Items()[2].(forecastItem).Title()
+	m, _ = click(m, lineOf(locationView(m), target)+1)
+	if m.list.Index() != 2 || m.forecastChosen {
+		t.Fatalf("expected the third forecast to be selected, got %d", m.list.Index())
+	}
+
+	m.list.Select(4)
+	m, _ = click(m, lineOf(locationView(m), target))
+	if m.list.Index() != 2 {
+		t.Fatalf("clicking above the selection should select it, got %d", m.list.Index())
+	}
+
+	m, _ = click(m, lineOf(locationView(m), target))
+	if !m.forecastChosen {
+		t.Error("clicking the selected forecast should open it")
+	}
+}
+
+func TestStripeTable(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	// the styling that starts a line, up to the text it wraps
+	opening := func(style lipgloss.Style) string {
+		styled := style.Render("x")
+		return styled[:strings.Index(styled, "x")]
+	}
+	first := opening(lipgloss.NewStyle().Background(lipgloss.Color(colorPalette[stripe])))
+	second := opening(lipgloss.NewStyle().Background(lipgloss.Color(colorPalette[stripeAlt])))
+	highlight := opening(tableStyleFocussed.Selected)
+
+	lineWith := func(view, name string) string {
+		return strings.Split(view, "\n")[findLine(screenLines(view), func(line string) bool {
+			return strings.Contains(line, name)
+		})]
+	}
+
+	full := Rows{{"Aberdeen", "1", "gr"}, {"Belfast", "2", "ni"}, {"Cardiff", "3", "wl"}}
+	m := focusTable(model{table: setupTable(full, 0), tableRows: full})
+	m.table.SetCursor(1)
+
+	view := stripeTable(m)
+	for name, want := range map[string]string{"Aberdeen": first, "Belfast": highlight, "Cardiff": first} {
+		if line := lineWith(view, name); !strings.HasPrefix(line, want) {
+			t.Errorf("expected %s to start with %q, got %q", name, want, line)
+		}
+	}
+
+	// filtering changes which rows share a stripe, and an unfocused
+	// selection is striped like any other row
+	m = showRows(m, full[1:])
+	m.table.Blur()
+	m.table.SetCursor(0)
+
+	view = stripeTable(m)
+	for name, want := range map[string]string{"Belfast": first, "Cardiff": second} {
+		if line := lineWith(view, name); !strings.HasPrefix(line, want) {
+			t.Errorf("expected %s to start with %q, got %q", name, want, line)
+		}
+	}
+}
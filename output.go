@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+// fetch the sitelist and write the sites matching query to w, one per
+// line as tab separated name, id and region, best match first, the
+// names are as the sitelist gives them as the region is alongside
+func writeMatchingSites(ctx context.Context, w io.Writer, m model, query string) error {
+	rows, _, _, err := m.getSitelist(ctx)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, row := range rows {
+		names = append(names, row[nameColumn])
+	}
+
+	// scripts get every match
+	for _, row := range rankMatches(query, rows, names, 0, 0) {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", placename(row), row[idColumn], row[regionColumn]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// a flattened forecast along with the day it's for, the API only
+// gives the date on the enclosing period
+type datedForecast struct {
+	Date string `json:"date"`
+	forecastData
+}
+
+// fetch a site's forecast and write it to w as a JSON array, for use
+// without the TUI
+func writeForecastJSON(ctx context.Context, w io.Writer, m model, locationId string, res resolution) error {
+	siteData, err := m.getSiteData(ctx, locationId, res)
+	if err != nil {
+		return err
+	}
+
+	// scripts would have to look out for the placeholders the views
+	// show, so missing values are left out instead
+	forecasts := []datedForecast{}
+	for _, period := range siteData.Site.Info.Location.Periods {
+		for _, f := range period.Forecasts {
+			fd := forecastValues(res, siteData.Site.MetaInfo, f)
+			forecasts = append(forecasts, datedForecast{Date: period.Date, forecastData: fd})
+		}
+	}
+
+	if len(forecasts) == 0 {
+		return fmt.Errorf("no forecast data available for location %s", locationId)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(forecasts)
+}
+
+// write what the metrics counted to path, or to stderr for "-"
+func writeMetrics(path string, metrics *data.Metrics) {
+	if path == "-" {
+		if err := metrics.Write(os.Stderr); err != nil {
+			slog.Error("writing metrics", "err", err)
+		}
+		return
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		slog.Error("writing metrics", "path", path, "err", err)
+		fmt.Fprintln(os.Stderr, "Not writing metrics:", err)
+		return
+	}
+	defer file.Close()
+
+	if err := metrics.Write(file); err != nil {
+		slog.Error("writing metrics", "path", path, "err", err)
+		fmt.Fprintln(os.Stderr, "Not writing metrics:", err)
+	}
+}
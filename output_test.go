@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jasonleelunn/forecast/internal/data"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+type failingSource struct{}
+
+func (failingSource) Get(ctx context.Context, url string) ([]byte, error) {
+	return nil, errors.New("no network")
+}
+
+func TestWriteForecastJSON(t *testing.T) {
+	m := model{fetcher: data.Offline{}}
+	var out bytes.Buffer
+	if err := writeForecastJSON(context.Background(), &out, m, "3772", dailyResolution); err != nil {
+		t.Fatal(err)
+	}
+
+	var forecasts []map[string]string
+	if err := json.Unmarshal(out.Bytes(), &forecasts); err != nil {
+		t.Fatalf("output isn't valid JSON: %v\n%s", err, out.String())
+	}
+
+	// the sample daily forecast has a day and a night for five days
+	if len(forecasts) != 10 {
+		t.Fatalf("expected 10 forecasts, got %d", len(forecasts))
+	}
+
+	first := forecasts[0]
+	if first["date"] != "2024-01-10Z" || first["time"] != "Day" || first["temperature"] == "" {
+		t.Errorf("unexpected first forecast %v", first)
+	}
+
+	// missing values are left out rather than given as placeholders
+	m.fetcher = data.SourceFunc(func(ctx context.Context, url string) ([]byte, error) {
+		if strings.Contains(url, "capabilities") {
+			return data.Offline{}.Get(ctx, url)
+		}
+		return []byte(`{"SiteRep": {"DV": {"Location": {"i": "3772", "Period":
+			[{"type": "Day", "value": "2024-01-10Z", "Rep": [{"$": "Day", "W": "7", "Dm": "9"}]}]}}}}`), nil
+	})
+	out.Reset()
+	if err := writeForecastJSON(context.Background(), &out, m, "3772", dailyResolution); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), missingValue) || strings.Contains(out.String(), `"visibility"`) {
+		t.Errorf("expected missing values to be left out, got:\n%s", out.String())
+	}
+
+	m.fetcher = failingSource{}
+	out.Reset()
+	if err := writeForecastJSON(context.Background(), &out, m, "3772", dailyResolution); err == nil {
+		t.Error("expected an error when the fetch fails")
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("nothing should be written on failure, got %q", out.String())
+	}
+}
+
+func TestWriteForecastJSONMetrics(t *testing.T) {
+	defer func(metrics *data.Metrics) { data.DefaultClient.Metrics = metrics }(data.DefaultClient.Metrics)
+	data.DefaultClient.Metrics = &data.Metrics{}
+	defer clearCapabilities()
+	clearCapabilities()
+
+	// the capabilities are fetched for the first forecast, and reused
+	// for the next
+	m := model{fetcher: data.Offline{}}
+	for _, locationId := range []string{"3772", "310002"} {
+		if err := writeForecastJSON(context.Background(), io.Discard, m, locationId, threeHourlyResolution); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if counts := data.DefaultClient.Metrics.Counts(); counts.CacheHits != 1 || counts.CacheMisses != 1 {
+		t.Errorf("expected a cache miss then a hit for the capabilities, got %+v", counts)
+	}
+}
+
+func TestWriteMatchingSites(t *testing.T) {
+	m := model{fetcher: data.Offline{}}
+	var out bytes.Buffer
+	if err := writeMatchingSites(context.Background(), &out, m, "newport"); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected both Newports, got:\n%s", out.String())
+	}
+	for _, line := range lines {
+		if fields := strings.Split(line, "\t"); len(fields) != 3 || fields[0] != "Newport" {
+			t.Errorf("expected a name, id and region, got %q", line)
+		}
+	}
+
+	// the sites come in the fuzzy matcher's ranking, as in the search
+	out.Reset()
+	if err := writeMatchingSites(context.Background(), &out, m, "e"); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, _, _, _ := m.getSitelist(context.Background())
+	var names []string
+	for _, row := range rows {
+		names = append(names, row[nameColumn])
+	}
+	ranks := fuzzy.RankFindFold("e", names)
+	sort.Sort(ranks)
+
+	var want strings.Builder
+	for _, rank := range ranks {
+		row := rows[rank.OriginalIndex]
+		want.WriteString(placename(row) + "\t" + row[idColumn] + "\t" + row[regionColumn] + "\n")
+	}
+	if len(ranks) < 3 || out.String() != want.String() {
+		t.Errorf("expected the ranked matches:\n%s\ngot:\n%s", want.String(), out.String())
+	}
+
+	out.Reset()
+	if err := writeMatchingSites(context.Background(), &out, m, "zzz"); err != nil || out.Len() != 0 {
+		t.Errorf("expected no output for no matches, got %q and %v", out.String(), err)
+	}
+}
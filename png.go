@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	imagecolor "image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	pngWidth  = 520
+	pngHeight = 220
+	pngMargin = 24
+	// the square the weather icon is drawn in
+	pngIconSize = 96
+)
+
+// a line of the card, drawn with its baseline at y
+type pngText struct {
+	text string
+	size float64
+	font *opentype.Font
+	c    color
+	x, y int
+}
+
+// fetch a site's forecast and write the slot under way to path as a PNG
+// card, for sharing without the TUI
+func writeForecastPNG(ctx context.Context, path string, m model, locationId string) error {
+	siteData, err := m.getSiteData(ctx, locationId, threeHourlyResolution)
+	if err != nil {
+		return err
+	}
+
+	forecast, slot, ok := currentSlot(siteData, clock())
+	if !ok {
+		return fmt.Errorf("no forecast data available for location %s", locationId)
+	}
+
+	m.siteData = siteData
+	fd := flattenForecast(threeHourlyResolution, siteData.Site.MetaInfo, forecast)
+
+	// render it first so a failure doesn't leave half an image behind
+	var b bytes.Buffer
+	if err := renderForecastPNG(&b, m, slot, fd); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("couldn't save the image: %w", err)
+	}
+
+	return nil
+}
+
+// draw one slot's forecast as a card in the theme's colours, the icon
+// is drawn from the same mapping as the emoji as the font has none
+func renderForecastPNG(w io.Writer, m model, slot time.Time, fd forecastData) error {
+	regular, err := loadFont(goregular.TTF)
+	if err != nil {
+		return err
+	}
+	bold, err := loadFont(gobold.TTF)
+	if err != nil {
+		return err
+	}
+
+	palette := themes[m.themeIndex].Palette
+	rgba := func(c color) imagecolor.Color { return hexColor(palette[c]) }
+
+	img := image.NewRGBA(image.Rect(0, 0, pngWidth, pngHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(rgba(stripe)), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(0, 0, pngWidth, 6), image.NewUniform(rgba(pink)), image.Point{}, draw.Src)
+
+	code := fd.WeatherCode
+	location := m.siteData.Site.Info.Location
+	if site, ok := parseCoordinates(location.Lat, location.Lon); ok {
+		code = weatherCodeAt(slot, site.lat, site.lon, code)
+	}
+	drawIcon(img, image.Pt(pngMargin, 84), weatherIcon(code), rgba)
+
+	conditions := describeCode(code, m.language)
+	if percent, err := fd.PrecipitationPct(); err == nil {
+		conditions += " · " + describeRain(percent)
+	}
+
+	textX := pngMargin + pngIconSize + pngMargin
+	lines := []pngText{
+		{m.siteData.Site.Info.Location.Name, 26, bold, grey, pngMargin, 46},
+		{slot.Format("Mon 02 Jan") + " " + clockTime(slot, m.twelveHour), 16, regular, grey, pngMargin, 72},
+		{formatTemp(fd.Temperature, m.tempUnit), 56, bold, tempBand(fd.Temperature), textX, 142},
+		{conditions, 18, regular, grey, textX, 174},
+	}
+	if fd.FeelsLikeTemp != "" {
+		lines = append(lines, pngText{"Feels like " + formatTemp(fd.FeelsLikeTemp, m.tempUnit), 16, regular, grey, textX, 198})
+	}
+
+	for _, line := range lines {
+		face, err := opentype.NewFace(line.font, &opentype.FaceOptions{Size: line.size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return fmt.Errorf("couldn't load the font: %w", err)
+		}
+
+		d := font.Drawer{Dst: img, Src: image.NewUniform(rgba(line.c)), Face: face, Dot: fixed.P(line.x, line.y)}
+		d.DrawString(fitText(face, line.text, pngWidth-pngMargin-line.x))
+		face.Close()
+	}
+
+	return png.Encode(w, img)
+}
+
+func loadFont(ttf []byte) (*opentype.Font, error) {
+	f, err := opentype.Parse(ttf)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't load the font: %w", err)
+	}
+
+	return f, nil
+}
+
+// text cut short with an ellipsis to fit in width pixels
+func fitText(face font.Face, text string, width int) string {
+	limit := fixed.I(width)
+	if font.MeasureString(face, text) <= limit {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && font.MeasureString(face, string(runes)+"…") > limit {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "…"
+}
+
+// a palette colour such as "#a9def9" or "#000", black if it's not one
+func hexColor(hex string) imagecolor.RGBA {
+	if len(hex) == 4 {
+		hex = string([]byte{hex[0], hex[1], hex[1], hex[2], hex[2], hex[3], hex[3]})
+	}
+
+	value, err := strconv.ParseUint(hex[min(1, len(hex)):], 16, 32)
+	if err != nil || len(hex) != 7 {
+		return imagecolor.RGBA{A: 0xff}
+	}
+
+	return imagecolor.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xff}
+}
+
+// draw a simple picture of the conditions an emoji stands for in the
+// icon square at origin
+func drawIcon(img *image.RGBA, origin image.Point, icon string, rgba func(color) imagecolor.Color) {
+	at := func(x, y int) image.Point { return origin.Add(image.Pt(x, y)) }
+
+	sun := func(x, y, r int) { fillCircle(img, at(x, y), r, rgba(yellow)) }
+	cloud := func() {
+		fillCircle(img, at(30, 50), 18, rgba(grey))
+		fillCircle(img, at(52, 40), 24, rgba(grey))
+		fillCircle(img, at(72, 52), 16, rgba(grey))
+		draw.Draw(img, image.Rectangle{at(30, 50), at(72, 68)}, image.NewUniform(rgba(grey)), image.Point{}, draw.Src)
+	}
+	rain := func() {
+		for i := 0; i < 4; i++ {
+			drawLine(img, at(26+i*16, 76), at(20+i*16, 90), 3, rgba(blue))
+		}
+	}
+	snow := func() {
+		for i := 0; i < 4; i++ {
+			fillCircle(img, at(24+i*16, 80+i%2*8), 4, rgba(blue))
+		}
+	}
+	bolt := func() {
+		drawLine(img, at(56, 66), at(46, 80), 4, rgba(amber))
+		drawLine(img, at(46, 80), at(58, 80), 4, rgba(amber))
+		drawLine(img, at(58, 80), at(46, 94), 4, rgba(amber))
+	}
+
+	switch icon {
+	case "☀️":
+		sun(48, 48, 28)
+	case "🌙":
+		fillCircle(img, at(48, 48), 28, rgba(grey))
+		fillCircle(img, at(62, 38), 24, rgba(stripe))
+	case "⛅":
+		sun(64, 30, 20)
+		cloud()
+	case "☁️":
+		cloud()
+	case "🌫️":
+		for i := 0; i < 4; i++ {
+			draw.Draw(img, image.Rectangle{at(12+i%2*10, 24+i*16), at(84-i%2*10, 30+i*16)}, image.NewUniform(rgba(grey)), image.Point{}, draw.Src)
+		}
+	case "🌦️":
+		sun(66, 28, 18)
+		cloud()
+		rain()
+	case "🌧️":
+		cloud()
+		rain()
+	case "🌨️", "❄️":
+		cloud()
+		snow()
+	case "⛈️":
+		cloud()
+		rain()
+		bolt()
+	case "🌩️":
+		cloud()
+		bolt()
+	}
+}
+
+func fillCircle(img *image.RGBA, centre image.Point, r int, c imagecolor.Color) {
+	for y := -r; y <= r; y++ {
+		for x := -r; x <= r; x++ {
+			if x*x+y*y <= r*r {
+				img.Set(centre.X+x, centre.Y+y, c)
+			}
+		}
+	}
+}
+
+// a line width pixels thick, drawn as squares stepped along it
+func drawLine(img *image.RGBA, from, to image.Point, width int, c imagecolor.Color) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	steps := max(dx, -dx, dy, -dy, 1)
+
+	for i := 0; i <= steps; i++ {
+		x := from.X + dx*i/steps
+		y := from.Y + dy*i/steps
+		draw.Draw(img, image.Rect(x, y, x+width, y+width), image.NewUniform(c), image.Point{}, draw.Src)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestWriteForecastPNG(t *testing.T) {
+	defer func(c func() time.Time) { clock = c }(clock)
+	clock = func() time.Time { return time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC) }
+
+	m, err := applyConfig(model{fetcher: data.Offline{}}, defaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "forecast.png")
+	if err := writeForecastPNG(context.Background(), path, m, "3772"); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("expected a valid PNG, got %v", err)
+	}
+	if size := img.Bounds().Size(); size.X != pngWidth || size.Y != pngHeight {
+		t.Errorf("expected a %dx%d image, got %v", pngWidth, pngHeight, size)
+	}
+
+	// the background is the theme's and the card has something drawn on it
+	background := hexColor(themes[m.themeIndex].Palette[stripe])
+	drawn := 0
+	for y := 0; y < pngHeight; y++ {
+		for x := 0; x < pngWidth; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			if uint8(r>>8) != background.R || uint8(g>>8) != background.G || uint8(b>>8) != background.B {
+				drawn++
+			}
+		}
+	}
+	if drawn < 1000 {
+		t.Errorf("expected the forecast to be drawn, only %d pixels were", drawn)
+	}
+
+	// a path that can't be written to is reported
+	missing := filepath.Join(t.TempDir(), "missing", "forecast.png")
+	if err := writeForecastPNG(context.Background(), missing, m, "3772"); err == nil {
+		t.Error("expected an error saving to a directory that doesn't exist")
+	}
+}
+
+func TestHexColor(t *testing.T) {
+	if got := hexColor("#a9def9"); got.R != 0xa9 || got.G != 0xde || got.B != 0xf9 {
+		t.Errorf("got %+v for #a9def9", got)
+	}
+	if got := hexColor("#fff"); got.R != 0xff || got.G != 0xff || got.B != 0xff {
+		t.Errorf("got %+v for #fff", got)
+	}
+	if got := hexColor("teal"); got.R != 0 || got.A != 0xff {
+		t.Errorf("expected black for a name, got %+v", got)
+	}
+}
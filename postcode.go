@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const postcodeUrl = "https://api.postcodes.io/postcodes/"
+
+var (
+	postcodePattern = regexp.MustCompile(`(?i)^[A-Z]{1,2}[0-9][A-Z0-9]? ?[0-9][A-Z]{2}$`)
+	// an outward code like "SW1A", with as much of the inward code as
+	// has been typed, for telling a mistyped postcode from a placename
+	partialPostcodePattern = regexp.MustCompile(`(?i)^[A-Z]{1,2}[0-9][A-Z0-9]?( ?[0-9][A-Z]{0,2})?$`)
+)
+
+type postcodeMsg struct {
+	postcode string
+	lat, lon float64
+	err      error
+}
+
+func isPostcode(s string) bool {
+	return postcodePattern.MatchString(strings.TrimSpace(s))
+}
+
+func looksLikePostcode(s string) bool {
+	return partialPostcodePattern.MatchString(strings.TrimSpace(s))
+}
+
+// look up the latitude and longitude of a UK postcode using postcodes.io
+func (m model) geocodePostcode(postcode string) (lat, lon float64, err error) {
+	body, err := m.fetcher.Get(context.Background(), postcodeUrl+url.PathEscape(strings.TrimSpace(postcode)))
+	if err != nil {
+		return 0, 0, fmt.Errorf("postcode lookup unavailable: %w", err)
+	}
+
+	var res struct {
+		Status int    `json:"status"`
+		Error  string `json:"error"`
+		Result struct {
+			Latitude  float64 `json:"latitude"`
+			Longitude float64 `json:"longitude"`
+		} `json:"result"`
+	}
+
+	err = json.Unmarshal(body, &res)
+	if err != nil {
+		return 0, 0, fmt.Errorf("postcode lookup unavailable: %w", err)
+	}
+
+	if res.Status != 200 {
+		return 0, 0, fmt.Errorf("postcode not found: %s", res.Error)
+	}
+
+	return res.Result.Latitude, res.Result.Longitude, nil
+}
+
+func lookupPostcode(m model, postcode string) tea.Cmd {
+	return func() tea.Msg {
+		lat, lon, err := m.geocodePostcode(postcode)
+		return postcodeMsg{postcode: postcode, lat: lat, lon: lon, err: err}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestIsPostcode(t *testing.T) {
+	for _, postcode := range []string{"SW1A 1AA", "m1 1ae", "LS29BE", "EC1A 1BB"} {
+		if !isPostcode(postcode) {
+			t.Errorf("%q should be a valid postcode", postcode)
+		}
+	}
+
+	for _, postcode := range []string{"12345", "SW1A", "Leeds 1", "ABC 123"} {
+		if isPostcode(postcode) {
+			t.Errorf("%q should not be a valid postcode", postcode)
+		}
+	}
+}
+
+func TestLooksLikePostcode(t *testing.T) {
+	// outward codes with or without part of the inward code
+	for _, query := range []string{"SW1A", "ls2", "M1 1", "LS2 9B", "EC1A 1BB"} {
+		if !looksLikePostcode(query) {
+			t.Errorf("%q should look like a postcode", query)
+		}
+	}
+
+	// searches that happen to contain digits are left as searches
+	for _, query := range []string{"12345", "Leeds 1", "region:se 2", "M1 motorway", "ABC 123"} {
+		if looksLikePostcode(query) {
+			t.Errorf("%q shouldn't look like a postcode", query)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+const (
+	// how long a row has to stay highlighted before its forecast is
+	// fetched, so scrolling past rows doesn't fetch them all
+	prefetchDelay = 400 * time.Millisecond
+	// a prefetched forecast older than this is fetched again on opening
+	prefetchCacheTime = 5 * time.Minute
+)
+
+// a forecast fetched for a highlighted row before it was chosen
+type prefetchedSite struct {
+	locationId string
+	resolution resolution
+	siteData   data.SiteData
+	fetched    time.Time
+}
+
+type prefetchTickMsg struct {
+	locationId string
+}
+
+type prefetchedMsg struct {
+	site prefetchedSite
+	err  error
+}
+
+// start the countdown to prefetching the highlighted row whenever the
+// highlight moves to a different one
+func watchHighlight(m model) (model, tea.Cmd) {
+	row := m.table.SelectedRow()
+	if !m.table.Focused() || m.locationChosen || row == nil || row[idColumn] == m.highlightedId {
+		return m, nil
+	}
+
+	id := row[idColumn]
+	m.highlightedId = id
+
+	return m, tea.Tick(prefetchDelay, func(time.Time) tea.Msg {
+		return prefetchTickMsg{locationId: id}
+	})
+}
+
+// whether the prefetched forecast can stand in for fetching the site
+func hasPrefetched(m model, locationId string) bool {
+	p := m.prefetched
+	return p.locationId == locationId && p.resolution == m.forecastResolution && time.Since(p.fetched) < prefetchCacheTime
+}
+
+// fetch the highlighted row's forecast if it's still highlighted once
+// the delay is up
+func handlePrefetchTick(m model, msg prefetchTickMsg) tea.Cmd {
+	if msg.locationId != m.highlightedId || m.locationChosen || hasPrefetched(m, msg.locationId) {
+		return nil
+	}
+
+	// there's no forecast to fetch for a site with only observations
+	if forecasts, _ := siteDataKinds(m.allRows, msg.locationId); !forecasts {
+		return nil
+	}
+
+	res := m.forecastResolution
+
+	return func() tea.Msg {
+		siteData, err := m.getSiteData(context.Background(), msg.locationId, res)
+		return prefetchedMsg{
+			site: prefetchedSite{locationId: msg.locationId, resolution: res, siteData: siteData, fetched: time.Now()},
+			err:  err,
+		}
+	}
+}
+
+// keep a prefetched forecast, unless the highlight has since moved on
+func handlePrefetched(m model, msg prefetchedMsg) model {
+	if msg.err != nil {
+		// choosing the site will fetch it again and report the error
+		slog.Debug("prefetch failed", "location", msg.site.locationId, "err", msg.err)
+		return m
+	}
+
+	if msg.site.locationId != m.highlightedId {
+		return m
+	}
+
+	m.prefetched = msg.site
+
+	return m
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+// the offline data, counting the forecasts asked for
+type countingSource struct {
+	forecasts *int
+}
+
+func (s countingSource) Get(ctx context.Context, url string) ([]byte, error) {
+	if strings.Contains(url, "wxfcs/all/json/") && !strings.Contains(url, "sitelist") && !strings.Contains(url, "capabilities") {
+		*s.forecasts++
+	}
+
+	return data.Offline{}.Get(ctx, url)
+}
+
+func TestPrefetch(t *testing.T) {
+	forecasts := 0
+	m := startedModel(defaultConfig(), countingSource{forecasts: &forecasts})
+	m = focusTable(m)
+
+	press := func(key tea.KeyType) tea.Cmd {
+		next, cmd := m.Update(tea.KeyMsg{Type: key})
+		m = next.(model)
+		return cmd
+	}
+
+	press(tea.KeyDown)
+	first := m.highlightedId
+	if first == "" || first != m.table.SelectedRow()[idColumn] {
+		t.Fatalf("expected the highlighted row to be tracked, got %q", first)
+	}
+
+	// scrolling on before the delay is up drops the first prefetch
+	press(tea.KeyDown)
+	if cmd := handlePrefetchTick(m, prefetchTickMsg{locationId: first}); cmd != nil {
+		t.Error("expected no prefetch for a row scrolled past")
+	}
+
+	second := m.highlightedId
+	cmd := handlePrefetchTick(m, prefetchTickMsg{locationId: second})
+	if cmd == nil {
+		t.Fatal("expected the row still highlighted to be prefetched")
+	}
+	msg := cmd().(prefetchedMsg)
+
+	// a prefetch that lands after the highlight has moved isn't kept
+	press(tea.KeyUp)
+	if got := handlePrefetched(m, msg); got.prefetched.locationId != "" {
+		t.Error("expected a prefetch for a row scrolled away from to be discarded")
+	}
+
+	press(tea.KeyDown)
+	m = handlePrefetched(m, msg)
+	if m.prefetched.locationId != second || forecasts != 1 {
+		t.Fatalf("expected one forecast to be prefetched, got %d", forecasts)
+	}
+
+	press(tea.KeyEnter)
+	if m.loading || len(m.list.Items()) == 0 || m.locationId != second {
+		t.Error("expected the prefetched forecast to be shown straight away")
+	}
+	if forecasts != 1 {
+		t.Errorf("expected the prefetched forecast to be used, got %d fetches", forecasts)
+	}
+}
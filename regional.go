@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+// the sitelist gives regions as short codes, the regional forecasts
+// are requested by number
+var regionIds = map[string]string{
+	"os": "500",
+	"he": "501",
+	"gr": "502",
+	"ta": "503",
+	"st": "504",
+	"dg": "505",
+	"ni": "506",
+	"yh": "507",
+	"ne": "508",
+	"em": "509",
+	"ee": "510",
+	"se": "511",
+	"nw": "512",
+	"wm": "513",
+	"sw": "514",
+	"wl": "515",
+	"uk": "516",
+}
+
+// what the region codes stand for, to tell apart sites sharing a name
+var regionNames = map[string]string{
+	"os": "Orkney & Shetland",
+	"he": "Highland & Eilean Siar",
+	"gr": "Grampian",
+	"ta": "Tayside",
+	"st": "Strathclyde",
+	"dg": "Dumfries, Lothian & Borders",
+	"ni": "Northern Ireland",
+	"yh": "Yorkshire & Humber",
+	"ne": "North East England",
+	"em": "East Midlands",
+	"ee": "East of England",
+	"se": "London & South East England",
+	"nw": "North West England",
+	"wm": "West Midlands",
+	"sw": "South West England",
+	"wl": "Wales",
+	"uk": "UK",
+}
+
+// a region code's name, or the code itself if it isn't known
+func regionName(code string) string {
+	if name, ok := regionNames[code]; ok {
+		return name
+	}
+
+	return strings.ToUpper(code)
+}
+
+// lines taken by the title and hint around the regional text
+const regionalChrome = 4
+
+type regionalTextMsg struct {
+	id   int
+	text string
+	err  error
+}
+
+// the region id for a site, falling back to the UK wide forecast
+func regionIdFor(allRows Rows, siteId string) string {
+	if id, ok := regionIds[regionCodeFor(allRows, siteId)]; ok {
+		return id
+	}
+
+	return regionIds["uk"]
+}
+
+// the paragraphs of a regional text forecast, each under its title
+func (m model) getRegionalText(ctx context.Context, regionId string) (string, error) {
+	url := makeUrl("txt/wxfcs/regionalforecast/json/" + regionId)
+
+	res, err := m.fetcher.Get(ctx, url)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch regional forecast: %w", err)
+	}
+
+	var regional data.RegionalForecast
+	err = json.Unmarshal(res, &regional)
+	if err != nil {
+		return "", fmt.Errorf("error decoding JSON: %w", err)
+	}
+
+	var paragraphs []string
+	for _, period := range regional.Forecast.Periods.Period {
+		for _, paragraph := range period.Paragraphs {
+			paragraphs = append(paragraphs, paragraph.Title+"\n"+paragraph.Text)
+		}
+	}
+
+	if len(paragraphs) == 0 {
+		return "", fmt.Errorf("no regional forecast available for region %s", regionId)
+	}
+
+	return strings.Join(paragraphs, "\n\n"), nil
+}
+
+func fetchRegionalText(m model) tea.Cmd {
+	id, ctx, regionId := m.sessionId, m.ctx, regionIdFor(m.allRows, m.locationId)
+
+	return func() tea.Msg {
+		text, err := m.getRegionalText(ctx, regionId)
+		return regionalTextMsg{id: id, text: text, err: err}
+	}
+}
+
+// size the regional text to the terminal, re-wrapping the paragraphs
+func layoutRegional(m model) model {
+	h, v := listStyle.GetFrameSize()
+	width := max(1, m.width-h)
+
+	m.regional.Width = width
+	m.regional.Height = max(1, m.height-v-regionalChrome)
+	m.regional.SetContent(lipgloss.NewStyle().Width(width).Render(m.regionalText))
+
+	return m
+}
+
+func openRegional(m model, text string) model {
+	m.regionalChosen = true
+	m.regionalText = text
+	m.regional = viewport.New(0, 0)
+
+	return layoutRegional(m)
+}
+
+func updateRegional(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "esc" {
+		m.regionalChosen = false
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.regional, cmd = m.regional.Update(msg)
+
+	return m, cmd
+}
+
+func regionalView(m model) string {
+	title := m.siteData.Site.Info.Location.Name + " - Regional forecast"
+
+	hint := "↑/↓ to scroll, esc to go back, q to quit"
+	if !m.regional.AtBottom() {
+		hint = fmt.Sprintf("%3.f%%  ", m.regional.ScrollPercent()*100) + hint
+	}
+	hint = lipgloss.NewStyle().Foreground(paletteColor(grey)).Render(hint)
+
+	return listStyle.Render(title + "\n\n" + m.regional.View() + "\n" + hint)
+}
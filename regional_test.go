@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestRegionalForecast(t *testing.T) {
+	m := startedModel(defaultConfig(), data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 60, Height: 20})
+	m = next.(model)
+
+	// Leeds is in Yorkshire and the Humber, unknown sites get the UK forecast
+	if id := regionIdFor(m.allRows, "310002"); id != "507" {
+		t.Errorf("expected Leeds to be in region 507, got %s", id)
+	}
+	if id := regionIdFor(m.allRows, "0"); id != "516" {
+		t.Errorf("expected the UK wide region for an unknown site, got %s", id)
+	}
+
+	text, err := m.getRegionalText(context.Background(), "507")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Headline:", "Tonight:", "Outlook for Friday to Sunday:"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in the regional text", want)
+		}
+	}
+
+	m = runCmd(chooseLocation(m, "310002"))
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	m = next.(model)
+	for _, c := range cmd().(tea.BatchMsg) {
+		if c != nil {
+			next, _ = m.Update(c())
+			m = next.(model)
+		}
+	}
+
+	if !m.regionalChosen {
+		t.Fatal("expected the regional forecast to be open")
+	}
+
+	// the paragraphs are wrapped to the terminal and scroll
+	for _, line := range strings.Split(regionalView(m), "\n") {
+		if len([]rune(line)) > 60 {
+			t.Errorf("line wider than the terminal: %q", line)
+		}
+	}
+	if m.regional.AtBottom() {
+		t.Error("expected the regional text to need scrolling")
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = next.(model)
+	if m.regionalChosen || !m.locationChosen {
+		t.Error("esc should go back to the forecast list")
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Session is where the user left off, saved to the config on quitting
+// and picked back up with -resume
+type Session struct {
+	// the last location viewed, if any
+	Location        string `json:"location,omitempty"`
+	Resolution      string `json:"resolution"`
+	TemperatureUnit string `json:"temperatureUnit"`
+	WindUnit        string `json:"windUnit"`
+}
+
+func sessionFrom(m model) Session {
+	return Session{
+		Location:        m.locationId,
+		Resolution:      string(m.forecastResolution),
+		TemperatureUnit: strings.TrimPrefix(string(m.tempUnit), "°"),
+		WindUnit:        string(m.windUnit),
+	}
+}
+
+func saveSession(session Session) error {
+	return updateConfig(func(cfg *Config) { cfg.Session = &session })
+}
+
+// the config with the saved session's settings in place of its own
+func resumeSession(cfg Config) Config {
+	if cfg.Session == nil {
+		return cfg
+	}
+
+	if cfg.Session.Location != "" {
+		cfg.Location = cfg.Session.Location
+	}
+	cfg.Resolution = cfg.Session.Resolution
+	cfg.TemperatureUnit = cfg.Session.TemperatureUnit
+	cfg.WindUnit = cfg.Session.WindUnit
+
+	return cfg
+}
+
+// remember the session then quit, there's nothing worth saving until
+// the sitelist has loaded
+func quit(m model) tea.Cmd {
+	if len(m.allRows) > 0 {
+		if err := saveSession(sessionFrom(m)); err != nil {
+			slog.Warn("could not save session", "err", err)
+		}
+	}
+
+	// settings are saved on leaving them, which quitting skips
+	if m.settingsChosen {
+		closeSettings(m)
+	}
+
+	return tea.Quit
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestSessionRoundTrip(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	session := Session{Location: "310002", Resolution: "3hourly", TemperatureUnit: "F", WindUnit: "kt"}
+	if err := saveSession(session); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Session == nil || *cfg.Session != session {
+		t.Fatalf("expected to read back %+v, got %+v", session, cfg.Session)
+	}
+
+	// the session replaces the settings, which stay valid
+	m, err := applyConfig(model{}, resumeSession(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resumed := sessionFrom(m); resumed.Resolution != session.Resolution || resumed.TemperatureUnit != "F" || resumed.WindUnit != "kt" {
+		t.Errorf("expected the session's settings, got %+v", resumed)
+	}
+	if resumeSession(cfg).Location != "310002" {
+		t.Error("expected to resume at the last location")
+	}
+}
+
+func TestQuitSavesSession(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	m := startedModel(defaultConfig(), data.Offline{})
+	m = runCmd(chooseLocation(m, "310002"))
+
+	// quitting from the error view still saves
+	m.err = errors.New("something broke")
+	if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlC}); cmd == nil {
+		t.Fatal("expected ctrl+c to quit")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Session == nil || cfg.Session.Location != "310002" || cfg.Session.Resolution != "daily" {
+		t.Errorf("expected the session to be saved, got %+v", cfg.Session)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// the settings listed on the settings screen, in order
+type setting int
+
+const (
+	tempUnitSetting setting = iota
+	windUnitSetting
+	themeSetting
+	refreshSetting
+)
+
+// the keys changing a setting wherever they're pressed
+var settingShortcuts = map[string]setting{
+	"c": tempUnitSetting,
+	"m": windUnitSetting,
+	"t": themeSetting,
+}
+
+// the auto-refresh intervals enter steps through, 0 for off
+var refreshChoices = []time.Duration{0, 5 * time.Minute, 15 * time.Minute, 30 * time.Minute, time.Hour}
+
+type settingItem struct {
+	setting setting
+	title   string
+	value   string
+}
+
+func (i settingItem) Title() string       { return i.title }
+func (i settingItem) Description() string { return i.value }
+func (i settingItem) FilterValue() string { return i.title }
+
+func settingItems(m model) []list.Item {
+	return []list.Item{
+		settingItem{tempUnitSetting, "Temperature unit", string(m.tempUnit)},
+		settingItem{windUnitSetting, "Wind unit", string(m.windUnit)},
+		settingItem{themeSetting, "Theme", themes[m.themeIndex].Name},
+		settingItem{refreshSetting, "Auto-refresh", describeRefresh(m.refreshInterval)},
+	}
+}
+
+func describeRefresh(interval time.Duration) string {
+	if interval <= 0 {
+		return "off"
+	}
+
+	return "every " + strconv.Itoa(int(interval/time.Minute)) + " minutes"
+}
+
+func setupSettings() list.Model {
+	li := list.New(nil, newListDelegate(false), 0, 0)
+	li.Title = "Settings"
+	li.Styles.Title = listTitleStyle()
+	li.SetFilteringEnabled(false)
+	li.SetShowStatusBar(false)
+	// esc leaves the settings rather than quitting
+	li.KeyMap.Quit.Unbind()
+	li.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "change")),
+			key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+		}
+	}
+
+	return li
+}
+
+func layoutSettings(m model) model {
+	h, v := listStyle.GetFrameSize()
+	m.settings.SetSize(m.width-h, m.height-v)
+
+	return m
+}
+
+// show the settings over whatever view is open, which is returned to
+// once they're closed
+func openSettings(m model) model {
+	m.settingsChosen = true
+	m.settings = setupSettings()
+	m.settings.SetItems(settingItems(m))
+
+	return layoutSettings(m)
+}
+
+func updateSettings(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		s, change := settingShortcuts[msg.String()]
+
+		switch msg.String() {
+		case "esc", "s":
+			return closeSettings(m), nil
+		case "enter":
+			var item settingItem
+			item, change = m.settings.SelectedItem().(settingItem)
+			s = item.setting
+		}
+
+		if change {
+			m, cmd := changeSetting(m, s)
+
+			return m, tea.Batch(cmd, m.settings.SetItems(settingItems(m)))
+		}
+	}
+
+	var cmd tea.Cmd
+	m.settings, cmd = m.settings.Update(msg)
+
+	return m, cmd
+}
+
+// step a setting on to its next value, re-rendering anything showing it
+func changeSetting(m model, s setting) (model, tea.Cmd) {
+	switch s {
+	case tempUnitSetting:
+		return toggleTempUnit(m)
+	case windUnitSetting:
+		return cycleWindUnit(m)
+	case themeSetting:
+		m = cycleTheme(m)
+		m.settings.Styles.Title = listTitleStyle()
+		m.settings.SetDelegate(newListDelegate(false))
+
+		return m, nil
+	case refreshSetting:
+		return cycleRefresh(m)
+	}
+
+	return m, nil
+}
+
+func cycleRefresh(m model) (model, tea.Cmd) {
+	next := 0
+	for i, interval := range refreshChoices {
+		if interval == m.refreshInterval {
+			next = (i + 1) % len(refreshChoices)
+		}
+	}
+
+	m.refreshInterval = refreshChoices[next]
+
+	// replacing any refresh already scheduled, so the new interval
+	// applies straight away
+	if m.locationChosen {
+		return scheduleRefresh(m)
+	}
+
+	return m, nil
+}
+
+func closeSettings(m model) model {
+	m.settingsChosen = false
+
+	if err := saveSettings(m); err != nil {
+		slog.Warn("could not save settings", "err", err)
+		m.notice = "Couldn't save the settings: " + err.Error()
+	}
+
+	return m
+}
+
+func saveSettings(m model) error {
+	return updateConfig(func(cfg *Config) {
+		cfg.TemperatureUnit = strings.TrimPrefix(string(m.tempUnit), "°")
+		cfg.WindUnit = string(m.windUnit)
+		cfg.Theme = themes[m.themeIndex].Name
+		cfg.RefreshMinutes = int(m.refreshInterval / time.Minute)
+	})
+}
+
+func settingsView(m model) string {
+	return listStyle.Render(m.settings.View())
+}
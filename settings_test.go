@@ -0,0 +1,88 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestSettings(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	defer applyTheme(themes[0])
+
+	m := startedModel(defaultConfig(), data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = runCmd(chooseLocation(next.(model), "310002"))
+
+	var cmd tea.Cmd
+	press := func(keys ...tea.KeyMsg) {
+		for _, key := range keys {
+			next, cmd = m.Update(key)
+			m = next.(model)
+		}
+	}
+	enter, down := tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyDown}
+
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if !m.settingsChosen || !strings.Contains(m.View(), "Temperature unit") {
+		t.Fatalf("expected the settings, got:\n%s", m.View())
+	}
+
+	// each option shows its value and steps on to the next with enter
+	press(enter)
+	if m.tempUnit != fahrenheitUnit || !strings.Contains(m.View(), "°F") {
+		t.Errorf("expected °F to be chosen and shown, got:\n%s", m.View())
+	}
+
+	press(down, enter, down, enter, down, enter)
+	if m.windUnit != kphUnit || m.themeIndex != 1 || m.refreshInterval != 5*time.Minute {
+		t.Errorf("unexpected settings %s, %d and %v", m.windUnit, m.themeIndex, m.refreshInterval)
+	}
+	if cmd == nil {
+		t.Error("expected turning on auto-refresh to schedule one")
+	}
+
+	// the global shortcuts show their change in the list too
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if m.themeIndex != 0 || !m.settingsChosen || !strings.Contains(m.View(), themes[0].Name) {
+		t.Errorf("expected the next theme to be chosen and shown, got:\n%s", m.View())
+	}
+	press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+
+	// each change of interval replaces the refresh scheduled before
+	stale := refreshTickMsg{id: m.sessionId, refresh: m.refreshId}
+	m, _ = scheduleRefresh(m)
+	if _, cmd := m.Update(stale); cmd != nil {
+		t.Error("expected the replaced refresh's tick to be ignored")
+	}
+	if _, cmd := m.Update(refreshTickMsg{id: m.sessionId, refresh: m.refreshId}); cmd == nil {
+		t.Error("expected the latest refresh's tick to fetch the forecast")
+	}
+
+	// leaving goes back to the forecasts, listed in the new units
+	press(tea.KeyMsg{Type: tea.KeyEsc})
+	if m.settingsChosen || !m.locationChosen {
+		t.Fatal("expected to return to the location's forecasts")
+	}
+	if item := m.list.Items()[0].(forecastItem); !strings.Contains(item.title, "°F") {
+		t.Errorf("expected the forecasts in °F, got %q", item.title)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TemperatureUnit != "F" || cfg.WindUnit != "km/h" || cfg.Theme != themes[1].Name || cfg.RefreshMinutes != 5 {
+		t.Errorf("expected the settings to be saved, got %+v", cfg)
+	}
+
+	// settings are reopened where they were left
+	m, _ = applyConfig(model{}, cfg)
+	if m.refreshInterval != 5*time.Minute || m.windUnit != kphUnit {
+		t.Errorf("expected the saved settings to be applied, got %v and %s", m.refreshInterval, m.windUnit)
+	}
+}
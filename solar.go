@@ -0,0 +1,94 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// the sun's centre is this far below the horizon at sunrise and sunset,
+// allowing for refraction and the size of its disc
+const horizonDegrees = -0.833
+
+// weather codes with separate night and day variants, night first
+var dayNightCodes = [][2]string{
+	{"0", "1"},
+	{"2", "3"},
+	{"9", "10"},
+	{"13", "14"},
+	{"16", "17"},
+	{"19", "20"},
+	{"22", "23"},
+	{"25", "26"},
+	{"28", "29"},
+}
+
+// the sun's elevation in degrees at a time and place, using the low
+// precision formulae from the Astronomical Almanac which are good to
+// around a degree
+func solarElevation(t time.Time, lat, lon float64) float64 {
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+	toDegrees := func(rad float64) float64 { return rad * 180 / math.Pi }
+
+	// days since midday on 1 Jan 2000
+	d := float64(t.Unix())/86400 + 2440587.5 - 2451545.0
+
+	meanAnomaly := toRadians(357.529 + 0.98560028*d)
+	meanLongitude := 280.459 + 0.98564736*d
+	eclipticLongitude := toRadians(meanLongitude + 1.915*math.Sin(meanAnomaly) + 0.020*math.Sin(2*meanAnomaly))
+	obliquity := toRadians(23.439 - 0.00000036*d)
+
+	rightAscension := toDegrees(math.Atan2(math.Cos(obliquity)*math.Sin(eclipticLongitude), math.Cos(eclipticLongitude)))
+	declination := math.Asin(math.Sin(obliquity) * math.Sin(eclipticLongitude))
+
+	siderealDegrees := (18.697374558 + 24.06570982441908*d) * 15
+	hourAngle := toRadians(siderealDegrees + lon - rightAscension)
+
+	latitude := toRadians(lat)
+	return toDegrees(math.Asin(math.Sin(latitude)*math.Sin(declination) +
+		math.Cos(latitude)*math.Cos(declination)*math.Cos(hourAngle)))
+}
+
+// whether t falls between sunrise and sunset
+func isDaylight(t time.Time, lat, lon float64) bool {
+	return solarElevation(t, lat, lon) > horizonDegrees
+}
+
+// swap a weather code for its day or night variant, codes without one
+// are returned as they are
+func dayNightCode(code string, daylight bool) string {
+	for _, pair := range dayNightCodes {
+		if code == pair[0] || code == pair[1] {
+			if daylight {
+				return pair[1]
+			}
+
+			return pair[0]
+		}
+	}
+
+	return code
+}
+
+// the weather code for a 3hourly or observed slot, matched to whether
+// the sun is up at the site then, daily forecasts already say whether
+// they're for the day or night
+func slotWeatherCode(m model, date time.Time, fd forecastData) string {
+	t, ok := localSlotTime(date, fd.Time)
+	if !ok {
+		return fd.WeatherCode
+	}
+
+	location := m.siteData.Site.Info.Location
+	site, ok := parseCoordinates(location.Lat, location.Lon)
+	if !ok {
+		return fd.WeatherCode
+	}
+
+	return weatherCodeAt(t, site.lat, site.lon, fd.WeatherCode)
+}
+
+// the weather code for a slot starting at slotTime, matched to whether
+// the sun is up then at lat, lon
+func weatherCodeAt(slotTime time.Time, lat, lon float64, code string) string {
+	return dayNightCode(code, isDaylight(slotTime, lat, lon))
+}
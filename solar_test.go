@@ -0,0 +1,80 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestSolarElevation(t *testing.T) {
+	// the sun reaches about 14° above Leeds at midday in mid January
+	noon := time.Date(2024, 1, 10, 12, 10, 0, 0, time.UTC)
+	if elevation := solarElevation(noon, 53.8, -1.55); math.Abs(elevation-14.3) > 0.5 {
+		t.Errorf("expected about 14.3°, got %.1f°", elevation)
+	}
+
+	// and sets around 16:10
+	if isDaylight(time.Date(2024, 1, 10, 16, 40, 0, 0, time.UTC), 53.8, -1.55) {
+		t.Error("expected the sun to have set")
+	}
+}
+
+func TestDayNightCode(t *testing.T) {
+	tests := []struct {
+		code     string
+		daylight bool
+		want     string
+	}{
+		{"1", false, "0"},
+		{"0", true, "1"},
+		{"2", true, "3"},
+		{"29", false, "28"},
+		{"28", false, "28"},
+		{"7", false, "7"},
+	}
+
+	for _, test := range tests {
+		if got := dayNightCode(test.code, test.daylight); got != test.want {
+			t.Errorf("%s in daylight %v: got %s, want %s", test.code, test.daylight, got, test.want)
+		}
+	}
+}
+
+func TestSlotWeatherCode(t *testing.T) {
+	m := model{list: setupList(), forecastResolution: threeHourlyResolution}
+	m.siteData.Site.Info.Location = data.Location{
+		Name: "LEEDS",
+		Lat:  "53.8",
+		Lon:  "-1.55",
+		Periods: []data.Period{{
+			Date: "2024-01-10Z",
+			Forecasts: []data.Forecast{
+				// both codes are for the wrong time of day
+				{Time: "0", WeatherCode: "1"},
+				{Time: "720", WeatherCode: "2"},
+			},
+		}},
+	}
+
+	items := getForecastListItems(m)
+	if len(items) != 2 {
+		t.Fatalf("expected 2 list items, got %d", len(items))
+	}
+
+	if desc := items[0].(forecastItem).Description(); !strings.HasPrefix(desc, "Clear night") {
+		t.Errorf("expected a night description at midnight, got %q", desc)
+	}
+
+	if desc := items[1].(forecastItem).Description(); !strings.HasPrefix(desc, "Partly cloudy (day)") {
+		t.Errorf("expected a day description at midday, got %q", desc)
+	}
+
+	m.list.SetItems(items)
+	m, _ = openForecast(m)
+	if m.forecastData.WeatherCode != "0" {
+		t.Errorf("expected the forecast view to use the night code, got %s", m.forecastData.WeatherCode)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+const (
+	// chance of rain (%) worth taking an umbrella for
+	umbrellaRainThreshold = 50
+	// the start of the Met Office's "High" UV band
+	sunscreenUVThreshold = 6
+	// feels like temperature (°C) at or below which to wrap up
+	wrapUpTempThreshold = 5
+	// a mild, dry spell is good for getting out
+	walkMinTemp       = 12
+	walkMaxTemp       = 22
+	walkRainThreshold = 20
+)
+
+// short tips for what to wear or do, none when nothing stands out
+func suggestions(fd forecastData) []string {
+	var tips []string
+
+	rain, rainErr := fd.PrecipitationPct()
+	if rainErr == nil && rain >= umbrellaRainThreshold {
+		tips = append(tips, "Bring an umbrella")
+	}
+
+	if uv, err := fd.UVIndex(); err == nil && uv >= sunscreenUVThreshold {
+		tips = append(tips, "Wear sunscreen")
+	}
+
+	// go by the actual temperature when there's no feels like value
+	temp, tempErr := fd.FeelsLikeC()
+	if tempErr != nil {
+		temp, tempErr = fd.TemperatureC()
+	}
+
+	if tempErr == nil && temp <= wrapUpTempThreshold {
+		tips = append(tips, "Wrap up warm")
+	}
+
+	mild := tempErr == nil && temp >= walkMinTemp && temp <= walkMaxTemp
+	dry := rainErr == nil && rain < walkRainThreshold
+	if mild && dry && !isWindy(fd) {
+		tips = append(tips, "Great for a walk")
+	}
+
+	return tips
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSuggestions(t *testing.T) {
+	tests := []struct {
+		name string
+		fd   forecastData
+		want []string
+	}{
+		{"nothing notable", forecastData{Temperature: "9", Precipitation: "30", UV: "2"}, nil},
+		{"rainy", forecastData{Temperature: "9", Precipitation: "60"}, []string{"Bring an umbrella"}},
+		{"sunny", forecastData{Temperature: "27", Precipitation: "0", UV: "7"}, []string{"Wear sunscreen"}},
+		{"cold and rainy", forecastData{Temperature: "7", FeelsLikeTemp: "3", Precipitation: "80"}, []string{"Bring an umbrella", "Wrap up warm"}},
+		{"cold without feels like", forecastData{Temperature: "-2", Precipitation: "10"}, []string{"Wrap up warm"}},
+		{"mild and dry", forecastData{Temperature: "17", Precipitation: "5", UV: "3"}, []string{"Great for a walk"}},
+		{"mild but windy", forecastData{Temperature: "17", Precipitation: "5", GustSpeed: "45"}, nil},
+		{"missing values", forecastData{Temperature: missingValue, Precipitation: missingValue}, nil},
+	}
+
+	for _, test := range tests {
+		if got := suggestions(test.fd); !slices.Equal(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
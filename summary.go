@@ -0,0 +1,223 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+const (
+	summaryLabelWidth  = 8
+	summaryColumnWidth = 12
+	// minutes past midnight used to pick a representative 3hourly slot
+	midday = 720
+)
+
+type daySummary struct {
+	date    time.Time
+	high    string
+	low     string
+	weather string
+	rain    string
+	// whether the wind veers or backs through the day
+	wind string
+}
+
+var weatherIcons = map[string]string{
+	"0":  "🌙",
+	"1":  "☀️",
+	"2":  "☁️",
+	"3":  "⛅",
+	"5":  "🌫️",
+	"6":  "🌫️",
+	"7":  "☁️",
+	"8":  "☁️",
+	"9":  "🌦️",
+	"10": "🌦️",
+	"11": "🌧️",
+	"12": "🌧️",
+	"13": "🌧️",
+	"14": "🌧️",
+	"15": "🌧️",
+	"16": "🌨️",
+	"17": "🌨️",
+	"18": "🌨️",
+	"19": "🌨️",
+	"20": "🌨️",
+	"21": "🌨️",
+	"22": "❄️",
+	"23": "❄️",
+	"24": "❄️",
+	"25": "❄️",
+	"26": "❄️",
+	"27": "❄️",
+	"28": "⛈️",
+	"29": "⛈️",
+	"30": "🌩️",
+}
+
+func weatherIcon(code string) string {
+	icon, ok := weatherIcons[code]
+	if !ok {
+		return "?"
+	}
+
+	return icon
+}
+
+// daily forecasts label their periods "Day" and "Night",
+// anything else is a time in minutes past midnight
+func resolutionOf(f data.Forecast) resolution {
+	if f.Time == "Day" || f.Time == "Night" {
+		return dailyResolution
+	}
+
+	return threeHourlyResolution
+}
+
+// aggregate each period's forecasts into a single summary for that day
+func summariseDays(siteData data.SiteData) []daySummary {
+	var summaries []daySummary
+
+	for _, period := range siteData.Site.Info.Location.Periods {
+		date, err := parsePeriodDate(period.Date)
+		if err != nil || len(period.Forecasts) == 0 {
+			continue
+		}
+
+		summary := daySummary{date: date}
+		high, low, rain := 0, 0, -1
+		haveTemp := false
+		closestToMidday := -1
+		var directions []string
+
+		for _, forecast := range period.Forecasts {
+			fd := flattenForecast(resolutionOf(forecast), siteData.Site.MetaInfo, forecast)
+
+			if temp, err := fd.TemperatureC(); err == nil {
+				if !haveTemp || temp > high {
+					high = temp
+				}
+				if !haveTemp || temp < low {
+					low = temp
+				}
+				haveTemp = true
+			}
+
+			if chance, err := fd.PrecipitationPct(); err == nil && chance > rain {
+				rain = chance
+			}
+
+			directions = append(directions, fd.WindDirection)
+
+			// prefer the daytime conditions to represent the day
+			if fd.Time == "Day" {
+				summary.weather = fd.WeatherCode
+				closestToMidday = 0
+			} else if minutes, err := fd.Minutes(); err == nil {
+				distance := max(minutes-midday, midday-minutes)
+				if closestToMidday < 0 || distance < closestToMidday {
+					summary.weather = fd.WeatherCode
+					closestToMidday = distance
+				}
+			} else if summary.weather == "" {
+				summary.weather = fd.WeatherCode
+			}
+		}
+
+		if haveTemp {
+			summary.high = strconv.Itoa(high)
+			summary.low = strconv.Itoa(low)
+		}
+
+		if rain >= 0 {
+			summary.rain = strconv.Itoa(rain)
+		}
+
+		summary.wind = windTrend(directions)
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries
+}
+
+// number of day columns that fit alongside the row labels
+func visibleSummaryDays(width int) int {
+	h, _ := listStyle.GetFrameSize()
+	return max(1, (width-h-summaryLabelWidth)/(summaryColumnWidth+2))
+}
+
+func setupSummaryTable(summaries []daySummary, offset int, width int, unit temperatureUnit, lang data.Language) table.Model {
+	columns := []table.Column{{Title: "", Width: summaryLabelWidth}}
+	highs := table.Row{"High"}
+	lows := table.Row{"Low"}
+	weather := table.Row{"Weather"}
+	rain := table.Row{"Rain"}
+	wind := table.Row{"Wind"}
+
+	end := min(len(summaries), offset+visibleSummaryDays(width))
+
+	for _, summary := range summaries[offset:end] {
+		columns = append(columns, table.Column{Title: summary.date.Format("Mon 02"), Width: summaryColumnWidth})
+		highs = append(highs, formatTemp(summary.high, unit))
+		lows = append(lows, formatTemp(summary.low, unit))
+		weather = append(weather, weatherIcon(summary.weather)+" "+describeCode(summary.weather, lang))
+		chance := missingValue
+		if percent, err := strconv.Atoi(summary.rain); err == nil {
+			chance = compactRain(percent)
+		}
+		rain = append(rain, chance)
+		wind = append(wind, summary.wind)
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithRows([]table.Row{highs, lows, weather, rain, wind}),
+		table.WithHeight(5),
+		table.WithFocused(false),
+	)
+	t.SetStyles(tableStyle)
+
+	return t
+}
+
+func updateSummary(msg tea.Msg, m model) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		days := len(summariseDays(m.siteData))
+
+		switch msg.String() {
+		case "left", "h":
+			m.summaryOffset = max(0, m.summaryOffset-1)
+		case "right", "l":
+			m.summaryOffset = max(0, min(days-visibleSummaryDays(m.width), m.summaryOffset+1))
+		case "esc":
+			m.summaryChosen = false
+		}
+	}
+
+	return m, nil
+}
+
+func summaryView(m model) string {
+	summaries := summariseDays(m.siteData)
+	title := m.siteData.Site.Info.Location.Name + " - Week ahead"
+
+	if len(summaries) == 0 {
+		return listStyle.Render(title + "\n\nNo forecast data available")
+	}
+
+	offset := min(m.summaryOffset, len(summaries)-1)
+	t := setupSummaryTable(summaries, offset, m.width, m.tempUnit, m.language)
+
+	hint := "esc to go back, q to quit"
+	if len(summaries) > visibleSummaryDays(m.width) {
+		hint = "← → to scroll days, " + hint
+	}
+
+	return listStyle.Render(title + "\n\n" + borderStyle.Render(t.View()) + "\n\n" + hint)
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+type Theme struct {
+	Name    string
+	Palette map[color]string
+}
+
+// the first theme is the default
+var themes = []Theme{
+	{
+		Name: "dark",
+		Palette: map[color]string{
+			black:     "#000",
+			white:     "#ffffff",
+			grey:      "#dddddf",
+			green:     "#98FF98",
+			blue:      "#a9def9",
+			yellow:    "#fcf6bd",
+			pink:      "#ff99c8",
+			purple:    "#e4c1f9",
+			stripe:    "#1c1c22",
+			stripeAlt: "#2a2a33",
+			amber:     "#ffb347",
+			red:       "#ff6b6b",
+		},
+	},
+	{
+		// deeper shades which stay readable on a light background
+		Name: "light",
+		Palette: map[color]string{
+			black:     "#000",
+			white:     "#ffffff",
+			grey:      "#5c5c66",
+			green:     "#2e9e5b",
+			blue:      "#1f78b4",
+			yellow:    "#b58900",
+			pink:      "#d6336c",
+			purple:    "#7b4fa8",
+			stripe:    "#f4f4f7",
+			stripeAlt: "#e4e4ea",
+			amber:     "#d9822b",
+			red:       "#c92a2a",
+		},
+	},
+}
+
+// set by -no-color or the NO_COLOR env var, everything is drawn in the
+// terminal's default colours with only bold, underlines and borders
+var noColor bool
+
+func init() {
+	applyTheme(themes[0])
+}
+
+// see https://no-color.org, it counts when set to anything but empty
+func noColorEnv() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// turn colour off for everything drawn from now on, keeping bold and
+// underlines even where NO_COLOR would have them dropped too
+func disableColor() {
+	noColor = true
+	if termenv.ColorProfile() != termenv.Ascii {
+		lipgloss.SetColorProfile(termenv.ANSI)
+	}
+}
+
+var sgrPattern = regexp.MustCompile(`\x1b\[([0-9;]*)m`)
+
+// remove the colours from text's escape sequences, for the components
+// with colours of their own rather than from the palette
+func stripColor(text string) string {
+	return sgrPattern.ReplaceAllStringFunc(text, func(seq string) string {
+		params := strings.Split(sgrPattern.FindStringSubmatch(seq)[1], ";")
+
+		var kept []string
+		for i := 0; i < len(params); i++ {
+			code, err := strconv.Atoi(params[i])
+			switch {
+			case err != nil:
+				kept = append(kept, params[i])
+			case code == 38 || code == 48:
+				// skip the rest of an extended colour, 5;n or 2;r;g;b
+				if i+1 < len(params) && params[i+1] == "5" {
+					i += 2
+				} else if i+1 < len(params) && params[i+1] == "2" {
+					i += 4
+				}
+			case code >= 30 && code <= 49, code >= 90 && code <= 107:
+			default:
+				kept = append(kept, params[i])
+			}
+		}
+
+		if len(kept) == 0 {
+			return ""
+		}
+
+		return "\x1b[" + strings.Join(kept, ";") + "m"
+	})
+}
+
+// the palette's colour c, or none at all without colour
+func paletteColor(c color) lipgloss.TerminalColor {
+	if noColor {
+		return lipgloss.NoColor{}
+	}
+
+	return lipgloss.Color(colorPalette[c])
+}
+
+func findTheme(name string) (int, bool) {
+	for i, theme := range themes {
+		if strings.EqualFold(theme.Name, name) {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func themeNames() string {
+	var names []string
+	for _, theme := range themes {
+		names = append(names, theme.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+// rebuild the package level styles from a theme's palette
+func applyTheme(theme Theme) {
+	colorPalette = theme.Palette
+
+	borderStyle = lipgloss.NewStyle().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(paletteColor(blue))
+
+	setupTableStyles()
+}
+
+func listTitleStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(paletteColor(black)).
+		Background(paletteColor(purple)).
+		Padding(0, 1)
+}
+
+// the expanded delegate leaves room for a second line of details under
+// each forecast
+func newListDelegate(expanded bool) list.DefaultDelegate {
+	d := list.NewDefaultDelegate()
+	if expanded {
+		d.SetHeight(3)
+	}
+
+	selected := paletteColor(pink)
+	d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(selected).BorderForeground(selected)
+	d.Styles.SelectedDesc = d.Styles.SelectedDesc.Foreground(selected).BorderForeground(selected)
+
+	return d
+}
+
+// switch to the next theme and restyle the components already built
+func cycleTheme(m model) model {
+	m.themeIndex = (m.themeIndex + 1) % len(themes)
+	applyTheme(themes[m.themeIndex])
+
+	if m.table.Focused() {
+		m.table.SetStyles(tableStyleFocussed)
+	} else {
+		m.table.SetStyles(tableStyle)
+	}
+
+	m.list.Styles.Title = listTitleStyle()
+	m.list.SetDelegate(newListDelegate(m.expanded))
+
+	return m
+}
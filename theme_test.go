@@ -0,0 +1,73 @@
+package main
+
+import (
+	"regexp"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jasonleelunn/forecast/internal/data"
+	"github.com/muesli/termenv"
+)
+
+func TestThemesDefineEveryColor(t *testing.T) {
+	for _, theme := range themes {
+		for c := black; c <= red; c++ {
+			if theme.Palette[c] == "" {
+				t.Errorf("theme %q has no colour for palette slot %d", theme.Name, c)
+			}
+		}
+	}
+}
+
+// SGR sequences setting a foreground or background colour
+var colorPattern = regexp.MustCompile(`\x1b\[(?:[0-9;]*;)?(?:3[0-9]|4[0-9]|9[0-7]|10[0-7])(?:;[0-9;]*)?m`)
+
+func TestNoColor(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer func() {
+		noColor = false
+		applyTheme(themes[0])
+	}()
+
+	m := startedModel(defaultConfig(), data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = next.(model)
+
+	// the views are coloured to begin with
+	if !colorPattern.MatchString(m.View()) {
+		t.Fatal("expected the search view to be coloured")
+	}
+
+	disableColor()
+	m = initialModel(defaultConfig())
+	m.fetcher = data.Offline{}
+	m = runCmd(m, loadSitelist(m))
+	next, _ = m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = next.(model)
+
+	views := map[string]string{"search": m.View()}
+
+	// warnings and temperatures are colour coded when there's colour
+	m = runCmd(chooseLocation(m, "310002"))
+	views["location"] = m.View()
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	views["forecast"] = m.View()
+
+	for name, view := range views {
+		if seq := colorPattern.FindString(view); seq != "" {
+			t.Errorf("expected no colours in the %s view, found %q in:\n%s", name, seq, view)
+		}
+	}
+
+	if !tableStyleFocussed.Selected.GetUnderline() {
+		t.Error("expected the selected row to be underlined instead")
+	}
+
+	if got := stripColor("\x1b[1;38;2;255;0;0;48;5;22;4mhot\x1b[0m"); got != "\x1b[1;4mhot\x1b[0m" {
+		t.Errorf("expected only the colours to be removed, got %q", got)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+// the units DataPoint has always used, for when a param is missing from
+// the metadata
+var defaultUnits = map[string]string{
+	"T": "C", "F": "C", "Dm": "C", "FDm": "C", "Nm": "C", "FNm": "C", "Dp": "C",
+	"S": "mph", "G": "mph", "Gn": "mph", "Gm": "mph",
+	"H": "%", "Hn": "%", "Hm": "%", "Pp": "%", "PPd": "%", "PPn": "%",
+	"P": "hpa", "Pt": "Pa/s", "V": "m", "D": "compass",
+}
+
+// the unit the API gives for a param, e.g. "C" for "T"
+func paramUnit(meta data.Meta, code string) string {
+	if param, ok := findParam(meta, code); ok && param.Units != "" {
+		return param.Units
+	}
+
+	return defaultUnits[code]
+}
+
+// a param's metadata, if the response included any
+func findParam(meta data.Meta, code string) (data.Param, bool) {
+	for _, param := range meta.Params {
+		if param.Name == code {
+			return param, true
+		}
+	}
+
+	return data.Param{}, false
+}
+
+// the params holding the values whose codes differ between daily day
+// and night forecasts and everything else
+type paramCodes struct {
+	temp, feelsLike, gust, precipitation, humidity string
+}
+
+func paramCodesFor(res resolution, forecastTime string) paramCodes {
+	switch {
+	case res == dailyResolution && forecastTime == "Day":
+		return paramCodes{temp: "Dm", feelsLike: "FDm", gust: "Gn", precipitation: "PPd", humidity: "Hn"}
+	case res == dailyResolution && forecastTime == "Night":
+		return paramCodes{temp: "Nm", feelsLike: "FNm", gust: "Gm", precipitation: "PPn", humidity: "Hm"}
+	default:
+		return paramCodes{temp: "T", feelsLike: "F", gust: "G", precipitation: "Pp", humidity: "H"}
+	}
+}
+
+// e.g. "(D, S)" for the params behind a value, for debugging how they're
+// mapped, a code missing from the metadata is marked with a "?" as the
+// value was worked out or guessed rather than given
+func describeCodes(meta data.Meta, codes ...string) string {
+	var marked []string
+	for _, code := range codes {
+		if _, ok := findParam(meta, code); !ok {
+			code += "?"
+		}
+		marked = append(marked, code)
+	}
+
+	return "(" + strings.Join(marked, ", ") + ")"
+}
+
+// the rest of the app works in °C and mph, so convert anything given
+// in other units, values in units we don't know are left alone
+func toCelsius(value string, unit string) string {
+	temp, err := parseValue("temperature", value)
+	if err != nil {
+		return value
+	}
+
+	switch strings.ToUpper(strings.TrimPrefix(unit, "°")) {
+	case "F":
+		return strconv.Itoa(int(math.Round(float64(temp-32) * 5 / 9)))
+	case "K":
+		return strconv.Itoa(int(math.Round(float64(temp) - 273.15)))
+	default:
+		return value
+	}
+}
+
+func toMph(value string, unit string) string {
+	speed, err := parseValue("wind speed", value)
+	if err != nil {
+		return value
+	}
+
+	var mph float64
+	switch strings.ToLower(unit) {
+	case "kph", "km/h", "kmh":
+		mph = float64(speed) / 1.609344
+	case "kt", "kts", "knots":
+		mph = float64(speed) / 0.868976
+	case "m/s":
+		mph = float64(speed) * 2.236936
+	default:
+		return value
+	}
+
+	return strconv.Itoa(int(math.Round(mph)))
+}
+
+// a unit as the API spells it, e.g. "C" or "hpa", as it's written
+// beside a value
+func unitLabel(unit string) string {
+	switch {
+	case strings.EqualFold(unit, "hpa"):
+		return "hPa"
+	case unit == "C" || unit == "F":
+		return "°" + unit
+	default:
+		return unit
+	}
+}
+
+// the unit of each kind of value at a resolution, e.g. "humidity": "%",
+// from the params the API sent with it. Daily night forecasts use
+// different params in the same units as the day's, so the day's stand
+// in for them. Values without a unit are left out
+func unitsForResolution(meta data.Meta, res resolution) map[string]string {
+	codes := paramCodesFor(res, "Day")
+	params := map[string]string{
+		"temperature":   codes.temp,
+		"feelsLike":     codes.feelsLike,
+		"gust":          codes.gust,
+		"precipitation": codes.precipitation,
+		"humidity":      codes.humidity,
+		"wind":          "S",
+		"visibility":    "V",
+		"pressure":      "P",
+		"dewPoint":      "Dp",
+	}
+
+	units := make(map[string]string)
+	for kind, code := range params {
+		if unit := unitLabel(paramUnit(meta, code)); unit != "" {
+			units[kind] = unit
+		}
+	}
+
+	return units
+}
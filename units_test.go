@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestParamUnit(t *testing.T) {
+	meta := data.Meta{Params: []data.Param{
+		{Name: "T", Units: "F", Description: "Temperature"},
+		{Name: "S", Units: "kph", Description: "Wind Speed"},
+	}}
+
+	tests := map[string]string{"T": "F", "S": "kph", "G": "mph", "Pp": "%", "unknown": ""}
+	for code, want := range tests {
+		if got := paramUnit(meta, code); got != want {
+			t.Errorf("%s: got %q, want %q", code, got, want)
+		}
+	}
+
+	if got := unitsForResolution(data.Meta{}, hourlyResolution)["pressure"]; got != "hPa" {
+		t.Errorf("expected pressure in hPa by default, got %q", got)
+	}
+}
+
+func TestUnitsForResolution(t *testing.T) {
+	want := map[string]string{"temperature": "°C", "gust": "mph", "precipitation": "%", "humidity": "%", "visibility": "m"}
+	m := model{fetcher: data.Offline{}}
+	for _, res := range []resolution{dailyResolution, threeHourlyResolution} {
+		siteData, err := m.getSiteData(context.Background(), "310002", res)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		units := unitsForResolution(siteData.Site.MetaInfo, res)
+		for kind, unit := range want {
+			if units[kind] != unit {
+				t.Errorf("%s %s: got %q, want %q", res, kind, units[kind], unit)
+			}
+		}
+	}
+
+	// the daily params are looked up, not the 3hourly ones
+	meta := data.Meta{Params: []data.Param{{Name: "PPd", Units: "percent"}, {Name: "Pp", Units: "%"}}}
+	if got := unitsForResolution(meta, dailyResolution)["precipitation"]; got != "percent" {
+		t.Errorf("expected the daily precipitation unit, got %q", got)
+	}
+	if got := unitsForResolution(meta, threeHourlyResolution)["precipitation"]; got != "%" {
+		t.Errorf("expected the 3hourly precipitation unit, got %q", got)
+	}
+}
+
+func TestFlattenForecastUnits(t *testing.T) {
+	meta := data.Meta{Params: []data.Param{
+		{Name: "T", Units: "F"},
+		{Name: "F", Units: "F"},
+		{Name: "S", Units: "kph"},
+		{Name: "G", Units: "m/s"},
+	}}
+
+	var f data.Forecast
+	f.WindSpeed = "32"
+	f.Hourly = data.Hourly{Temperature: "50", FeelsLikeTemp: "41", GustSpeed: "10"}
+
+	fd := flattenForecast(threeHourlyResolution, meta, f)
+	if fd.Temperature != "10" || fd.FeelsLikeTemp != "5" {
+		t.Errorf("expected temperatures converted to °C, got %q and %q", fd.Temperature, fd.FeelsLikeTemp)
+	}
+
+	if fd.WindSpeed != "20" || fd.GustSpeed != "22" {
+		t.Errorf("expected speeds converted to mph, got %q and %q", fd.WindSpeed, fd.GustSpeed)
+	}
+
+	// without metadata the usual units are assumed
+	fd = flattenForecast(threeHourlyResolution, data.Meta{}, f)
+	if fd.Temperature != "50" || fd.WindSpeed != "32" {
+		t.Errorf("expected values left as given, got %q and %q", fd.Temperature, fd.WindSpeed)
+	}
+}
+
+func TestDescribeCodes(t *testing.T) {
+	meta := data.Meta{Params: []data.Param{{Name: "D"}, {Name: "S"}}}
+
+	if got := describeCodes(meta, "D", "S"); got != "(D, S)" {
+		t.Errorf("got %q, want %q", got, "(D, S)")
+	}
+
+	// feels like is estimated for observations, which have no F
+	if got := describeCodes(meta, "F"); got != "(F?)" {
+		t.Errorf("expected a missing code to be marked, got %q", got)
+	}
+
+	if got := paramCodesFor(dailyResolution, "Night"); got.temp != "Nm" || got.gust != "Gm" {
+		t.Errorf("expected the night codes, got %+v", got)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// set when building a release, e.g.
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.date=$(date -u +%Y-%m-%d)"
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
+
+// e.g. "forecast v1.2.0 (commit 1a2b3c4, built 2024-01-10)", a plain
+// go build or go run fills in what it can from the build info
+func versionInfo(info *debug.BuildInfo) string {
+	v, c, d := version, commit, date
+
+	if info != nil {
+		if v == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+			v = info.Main.Version
+		}
+
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && c == "":
+				c = setting.Value[:min(7, len(setting.Value))]
+			case setting.Key == "vcs.time" && d == "":
+				d = setting.Value[:min(10, len(setting.Value))]
+			}
+		}
+	}
+
+	if c == "" {
+		c = "unknown"
+	}
+	if d == "" {
+		d = "unknown"
+	}
+
+	return fmt.Sprintf("forecast %s (commit %s, built %s)", v, c, d)
+}
+
+func buildInfo() *debug.BuildInfo {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	return info
+}
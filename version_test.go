@@ -0,0 +1,30 @@
+package main
+
+import (
+	"runtime/debug"
+	"testing"
+)
+
+func TestVersionInfo(t *testing.T) {
+	if got := versionInfo(nil); got != "forecast dev (commit unknown, built unknown)" {
+		t.Errorf("expected dev defaults, got %q", got)
+	}
+
+	info := &debug.BuildInfo{
+		Main: debug.Module{Version: "v1.2.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "1a2b3c4d5e6f"},
+			{Key: "vcs.time", Value: "2024-01-10T12:00:00Z"},
+		},
+	}
+	if got := versionInfo(info); got != "forecast v1.2.0 (commit 1a2b3c4, built 2024-01-10)" {
+		t.Errorf("expected the build info to be used, got %q", got)
+	}
+
+	// what's set with -ldflags wins
+	defer func(v, c, d string) { version, commit, date = v, c, d }(version, commit, date)
+	version, commit, date = "v2.0.0", "abcdef0", "2024-02-01"
+	if got := versionInfo(info); got != "forecast v2.0.0 (commit abcdef0, built 2024-02-01)" {
+		t.Errorf("expected the ldflags to win, got %q", got)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jasonleelunn/forecast/internal/data"
+	"github.com/muesli/termenv"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// compare a rendered view with testdata/name.golden, or rewrite it with
+// go test -run 'TestViewSnapshots|TestRenderKeyValues' -update
+func assertGolden(t *testing.T, name string, view string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(view), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing golden file, run with -update to create it: %v", err)
+	}
+
+	if view != string(want) {
+		t.Errorf("%s view doesn't match %s, got:\n%s\nwant:\n%s", name, path, view, want)
+	}
+}
+
+func TestViewSnapshots(t *testing.T) {
+	// render the same wherever and whenever the tests run
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.Ascii)
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local = time.UTC
+	defer func(c func() time.Time) { clock = c }(clock)
+	clock = func() time.Time { return time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC) }
+
+	m := startedModel(defaultConfig(), data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	m = next.(model)
+	assertGolden(t, "search", m.View())
+
+	m = runCmd(chooseLocation(m, "310002"))
+	assertGolden(t, "location", m.View())
+
+	m.list.Select(2)
+	m, _ = openForecast(m)
+	assertGolden(t, "forecast", m.View())
+}
+
+func TestRenderKeyValues(t *testing.T) {
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	fields := []keyValue{
+		{"Conditions", "Light rain"},
+		{"Chance of rain", renderPercent("40", "%", 10)},
+		{"UV", "1 (Low)"},
+		{"Dew point", formatTemp("3", celsiusUnit)},
+	}
+	assertGolden(t, "keyvalues", renderKeyValues(fields))
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jasonleelunn/forecast/internal/data"
+	"github.com/mattn/go-runewidth"
+)
+
+// warnings are published for the same regions as the sitelist uses,
+// along with one for the whole UK
+const (
+	warningsUrl      = "https://www.metoffice.gov.uk/public/data/PWSCache/WarningsRSS/Region/"
+	ukWarningsRegion = "UK"
+	// how long fetched warnings are reused before asking again
+	warningsCacheTime = 5 * time.Minute
+)
+
+var (
+	// e.g. "Yellow warning of rain affecting London & South East England"
+	warningTitlePattern = regexp.MustCompile(`(?i)^(yellow|amber|red) warning of (.+?) affecting`)
+	validityPattern     = regexp.MustCompile(`(?i)valid from .+$`)
+)
+
+type Warning struct {
+	// what the warning is for, e.g. "rain" or "wind"
+	Type string
+	// yellow, amber or red
+	Level    string
+	Validity string
+	Headline string
+}
+
+type warningsMsg struct {
+	region   string
+	warnings []Warning
+	err      error
+}
+
+// the sitelist region code for a site, falling back to the whole UK
+func regionCodeFor(allRows Rows, siteId string) string {
+	for _, row := range allRows {
+		if row[idColumn] == siteId && len(row) > regionColumn {
+			return row[regionColumn]
+		}
+	}
+
+	return ukWarningsRegion
+}
+
+func (m model) getWarnings(ctx context.Context, region string) ([]Warning, error) {
+	res, err := m.fetcher.Get(ctx, warningsUrl+region)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch warnings: %w", err)
+	}
+
+	var feed data.WarningsFeed
+	err = xml.Unmarshal(res, &feed)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding warnings: %w", err)
+	}
+
+	var warnings []Warning
+	for _, item := range feed.Items {
+		warning := Warning{Headline: strings.TrimSpace(item.Title)}
+
+		if match := warningTitlePattern.FindStringSubmatch(warning.Headline); match != nil {
+			warning.Level = strings.ToLower(match[1])
+			warning.Type = match[2]
+		}
+
+		warning.Validity = validityPattern.FindString(item.Description)
+		warnings = append(warnings, warning)
+	}
+
+	return warnings, nil
+}
+
+// fetch the warnings for the chosen site's region, unless they were
+// fetched recently
+func fetchWarnings(m model) tea.Cmd {
+	region := regionCodeFor(m.allRows, m.locationId)
+	if region == m.warningsRegion && time.Since(m.warningsFetched) < warningsCacheTime {
+		data.DefaultClient.Metrics.CacheHit()
+		return nil
+	}
+	data.DefaultClient.Metrics.CacheMiss()
+
+	ctx := m.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return func() tea.Msg {
+		warnings, err := m.getWarnings(ctx, region)
+		return warningsMsg{region: region, warnings: warnings, err: err}
+	}
+}
+
+// the warnings in force for the chosen site, none until they've been
+// fetched for its region
+func activeWarnings(m model) []Warning {
+	if m.warningsRegion != regionCodeFor(m.allRows, m.locationId) {
+		return nil
+	}
+
+	return m.warnings
+}
+
+func warningColor(level string) color {
+	switch level {
+	case "red":
+		return red
+	case "amber":
+		return amber
+	default:
+		return yellow
+	}
+}
+
+// a line for each warning coloured by its level, empty without any
+func warningsBanner(m model) string {
+	h, _ := listStyle.GetFrameSize()
+	width := max(1, m.width-h)
+
+	var lines []string
+	for _, warning := range activeWarnings(m) {
+		text := "⚠ " + warning.Headline
+		if warning.Validity != "" {
+			text += ", " + warning.Validity
+		}
+
+		// one line each, so the views can make room for them
+		if m.width > 0 {
+			text = runewidth.Truncate(text, width, "…")
+		}
+
+		lines = append(lines, lipgloss.NewStyle().
+			Bold(true).
+			Foreground(paletteColor(black)).
+			Background(paletteColor(warningColor(warning.Level))).
+			Render(text))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// size the forecast list to the space left around it
+func layoutList(m model) model {
+	h, v := listStyle.GetFrameSize()
+	m.list.SetSize(m.width-h, m.height-v-headerHeight-footerHeight-len(activeWarnings(m)))
+
+	return m
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jasonleelunn/forecast/internal/data"
+)
+
+func TestGetWarnings(t *testing.T) {
+	warnings, err := model{fetcher: data.Offline{}}.getWarnings(context.Background(), "yh")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Warning{
+		Type:     "wind",
+		Level:    "amber",
+		Validity: "valid from 1200 Thu 11 Jan to 0300 Fri 12 Jan",
+		Headline: "Amber warning of wind affecting Yorkshire & Humber",
+	}
+	if len(warnings) != 2 || warnings[1] != want {
+		t.Errorf("got %+v, want the second to be %+v", warnings, want)
+	}
+}
+
+func TestWarningsBanner(t *testing.T) {
+	m := startedModel(defaultConfig(), data.Offline{})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	m = next.(model)
+
+	if banner := warningsBanner(m); banner != "" {
+		t.Errorf("expected no banner before any warnings, got %q", banner)
+	}
+
+	m = runCmd(chooseLocation(m, "310002"))
+
+	view := locationView(m)
+	for _, want := range []string{"⚠ Yellow warning of rain", "⚠ Amber warning of wind"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the location view, got:\n%s", want, view)
+		}
+	}
+
+	if lines := strings.Count(view, "\n") + 1; lines > 30 {
+		t.Errorf("expected the list to make room for the banner, got %d lines", lines)
+	}
+
+	// warnings fetched a moment ago are reused
+	if fetchWarnings(m) != nil {
+		t.Error("expected recent warnings to be reused")
+	}
+
+	m.warningsFetched = time.Now().Add(-warningsCacheTime)
+	if fetchWarnings(m) == nil {
+		t.Error("expected stale warnings to be fetched again")
+	}
+
+	// a site in another region doesn't show them
+	m.locationId = "3772"
+	if banner := warningsBanner(m); banner != "" {
+		t.Errorf("expected no banner for another region, got %q", banner)
+	}
+}
+
+func TestWarningsMetrics(t *testing.T) {
+	defer func(metrics *data.Metrics) { data.DefaultClient.Metrics = metrics }(data.DefaultClient.Metrics)
+	data.DefaultClient.Metrics = &data.Metrics{}
+	defer clearCapabilities()
+	clearCapabilities()
+
+	m := startedModel(defaultConfig(), data.Offline{})
+	m = runCmd(chooseLocation(m, "310002"))
+
+	// the forecast, its capabilities and the warnings all had to be fetched
+	if counts := data.DefaultClient.Metrics.Counts(); counts.CacheHits != 0 || counts.CacheMisses != 3 {
+		t.Fatalf("expected 3 cache misses, got %+v", counts)
+	}
+
+	fetchWarnings(m)
+	if counts := data.DefaultClient.Metrics.Counts(); counts.CacheHits != 1 || counts.CacheMisses != 3 {
+		t.Errorf("expected recent warnings to count as a cache hit, got %+v", counts)
+	}
+
+	m.warningsFetched = time.Now().Add(-warningsCacheTime)
+	fetchWarnings(m)
+	if counts := data.DefaultClient.Metrics.Counts(); counts.CacheMisses != 4 {
+		t.Errorf("expected stale warnings to count as a cache miss, got %+v", counts)
+	}
+}